@@ -0,0 +1,428 @@
+package goukv
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCacheSize = 1024
+)
+
+// CacheOpts the options of the cache layer
+type CacheOpts struct {
+	// Size the maximum number of cached keys, 1024 if it isn't set
+	Size int
+
+	// TTL the maximum duration a result is cached for, results are cached until they are
+	// evicted, invalidated or the entry expires if it isn't set
+	TTL time.Duration
+
+	// NegativeTTL the duration the missing (not found or expired) keys are cached for,
+	// missing keys aren't cached if it isn't set
+	NegativeTTL time.Duration
+}
+
+// CacheStats the statistics of a cache
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+}
+
+// Cached a provider that caches the results of `Get` and `TTL` of the provider it wraps
+// in a bounded LRU, the cached keys are invalidated by `Put`, `Delete` and `Batch` calls
+// made through it, so writes made directly to the backend are only visible once the cached
+// result is evicted or its `TTL` is elapsed.
+type Cached struct {
+	provider Provider
+	cache    *lruCache
+}
+
+// NewCached wraps the specified provider by a cache configured using the specified options
+func NewCached(p Provider, opts CacheOpts) *Cached {
+	if opts.Size < 1 {
+		opts.Size = defaultCacheSize
+	}
+
+	return &Cached{
+		provider: p,
+		cache:    newLRUCache(opts),
+	}
+}
+
+// CacheStats returns the statistics of the cache
+func (c *Cached) CacheStats() CacheStats {
+	return c.cache.stats()
+}
+
+// Stats implements goukv.Stater, the cache statistics are merged into the wrapped provider's
+func (c *Cached) Stats() map[string]float64 {
+	stats := map[string]float64{}
+	for k, v := range Stats(c.provider) {
+		stats[k] = v
+	}
+
+	cacheStats := c.cache.stats()
+
+	stats["cache_hits"] = float64(cacheStats.Hits)
+	stats["cache_misses"] = float64(cacheStats.Misses)
+	stats["cache_evictions"] = float64(cacheStats.Evictions)
+	stats["cache_size"] = float64(cacheStats.Size)
+
+	return stats
+}
+
+// Unwrap implements goukv.Unwrapper
+func (c *Cached) Unwrap() Provider {
+	return c.provider
+}
+
+// WithContext implements goukv.ContextBinder, the returned provider shares the same cache
+func (c *Cached) WithContext(ctx context.Context) Provider {
+	return &Cached{
+		provider: WithContext(ctx, c.provider),
+		cache:    c.cache,
+	}
+}
+
+// Open implements goukv.Open, the opened provider gets its own cache using the same options
+func (c *Cached) Open(dsn *DSN) (Provider, error) {
+	p, err := c.provider.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCached(p, c.cache.opts), nil
+}
+
+// Put implements goukv.Put
+func (c *Cached) Put(e *Entry) error {
+	defer c.cache.remove(string(e.Key))
+
+	return c.provider.Put(e)
+}
+
+// Get implements goukv.Get
+func (c *Cached) Get(k []byte) ([]byte, error) {
+	key := string(k)
+
+	if item := c.cache.get(key, func(item *cacheItem) bool { return item.hasValue }); item != nil {
+		return copyBytes(item.value), item.valueErr
+	}
+
+	gen := c.cache.begin(key)
+
+	val, err := c.provider.Get(k)
+	if err != nil && err != ErrKeyNotFound && err != ErrKeyExpired {
+		c.cache.end(key, gen, nil)
+		return nil, err
+	}
+
+	item := &cacheItem{
+		value:    copyBytes(val),
+		valueErr: err,
+		hasValue: true,
+	}
+
+	// the expiration time is required to stop serving the value once the entry expires
+	if err == nil {
+		t, ttlErr := c.provider.TTL(k)
+		if ttlErr != nil && ttlErr != ErrKeyNotFound {
+			c.cache.end(key, gen, nil)
+			return val, nil
+		}
+
+		// the key has been deleted or expired in the meantime, so it is cached as not found
+		if ttlErr == ErrKeyNotFound {
+			item.value, item.valueErr = nil, ErrKeyNotFound
+		}
+
+		item.expires, item.ttlErr, item.hasTTL = t, ttlErr, true
+	}
+
+	c.cache.end(key, gen, item)
+
+	return val, err
+}
+
+// TTL implements goukv.TTL
+func (c *Cached) TTL(k []byte) (*time.Time, error) {
+	key := string(k)
+
+	if item := c.cache.get(key, func(item *cacheItem) bool { return item.hasTTL }); item != nil {
+		return item.expires, item.ttlErr
+	}
+
+	gen := c.cache.begin(key)
+
+	t, err := c.provider.TTL(k)
+	if err != nil && err != ErrKeyNotFound {
+		c.cache.end(key, gen, nil)
+		return nil, err
+	}
+
+	c.cache.end(key, gen, &cacheItem{
+		expires: t,
+		ttlErr:  err,
+		hasTTL:  true,
+	})
+
+	return t, err
+}
+
+// Delete implements goukv.Delete
+func (c *Cached) Delete(k []byte) error {
+	defer c.cache.remove(string(k))
+
+	return c.provider.Delete(k)
+}
+
+// Batch implements goukv.Batch
+func (c *Cached) Batch(entries []*Entry) error {
+	defer (func() {
+		for _, entry := range entries {
+			c.cache.remove(string(entry.Key))
+		}
+	})()
+
+	return c.provider.Batch(entries)
+}
+
+// CompareAndSwap implements goukv.CompareAndSwapper
+func (c *Cached) CompareAndSwap(e *Entry, old []byte) (bool, error) {
+	defer c.cache.remove(string(e.Key))
+
+	return CompareAndSwap(c.provider, e, old)
+}
+
+// Scan implements goukv.Scan, scans aren't cached
+func (c *Cached) Scan(opts ScanOpts) error {
+	return c.provider.Scan(opts)
+}
+
+// Close implements goukv.Close
+func (c *Cached) Close() error {
+	c.cache.clear()
+
+	return c.provider.Close()
+}
+
+// cacheItem a cached result, it holds the result of `Get` and/or `TTL`
+type cacheItem struct {
+	key string
+
+	value    []byte
+	valueErr error
+	hasValue bool
+
+	expires *time.Time
+	ttlErr  error
+	hasTTL  bool
+
+	cachedUntil time.Time
+}
+
+// negative whether the item represents a missing key
+func (i *cacheItem) negative() bool {
+	return i.valueErr != nil || i.ttlErr != nil
+}
+
+// valid whether the item could still be served
+func (i *cacheItem) valid(now time.Time) bool {
+	if !i.cachedUntil.IsZero() && !now.Before(i.cachedUntil) {
+		return false
+	}
+
+	return i.expires == nil || now.Before(*i.expires)
+}
+
+// fetch tracks the in flight reads of a key, its generation is bumped whenever the key is invalidated,
+// so a result read before a write isn't cached after the write invalidated the key
+type fetch struct {
+	gen  uint64
+	refs int
+}
+
+// lruCache a bounded least recently used cache
+type lruCache struct {
+	sync.Mutex
+
+	opts     CacheOpts
+	items    map[string]*list.Element
+	order    *list.List
+	inflight map[string]*fetch
+
+	// now returns the current time, it is replaced by the tests
+	now func() time.Time
+
+	hits, misses, evictions uint64
+}
+
+// newLRUCache initializes a new cache
+func newLRUCache(opts CacheOpts) *lruCache {
+	return &lruCache{
+		opts:     opts,
+		items:    map[string]*list.Element{},
+		order:    list.New(),
+		inflight: map[string]*fetch{},
+		now:      time.Now,
+	}
+}
+
+// get returns the item of the specified key if it is valid and has the required result
+func (c *lruCache) get(key string, has func(*cacheItem) bool) *cacheItem {
+	c.Lock()
+	defer c.Unlock()
+
+	elem, found := c.items[key]
+	if !found {
+		c.misses++
+		return nil
+	}
+
+	item := elem.Value.(*cacheItem)
+
+	if !item.valid(c.now()) {
+		c.order.Remove(elem)
+		delete(c.items, key)
+		c.misses++
+		return nil
+	}
+
+	if !has(item) {
+		c.misses++
+		return nil
+	}
+
+	c.order.MoveToFront(elem)
+	c.hits++
+
+	return item
+}
+
+// begin marks the start of a read of the specified key from the backend,
+// it returns the generation of the key that has to be passed to end
+func (c *lruCache) begin(key string) uint64 {
+	c.Lock()
+	defer c.Unlock()
+
+	f, found := c.inflight[key]
+	if !found {
+		f = &fetch{}
+		c.inflight[key] = f
+	}
+
+	f.refs++
+
+	return f.gen
+}
+
+// end marks the end of a read started by begin, the specified item is cached only if it isn't nil
+// and the key hasn't been invalidated since the read started
+func (c *lruCache) end(key string, gen uint64, item *cacheItem) {
+	c.Lock()
+	defer c.Unlock()
+
+	f := c.inflight[key]
+	if f.refs--; f.refs < 1 {
+		delete(c.inflight, key)
+	}
+
+	if item == nil || f.gen != gen {
+		return
+	}
+
+	c.set(key, item)
+}
+
+// set caches the specified item, replacing the previous one of the same key if any,
+// the cache must be locked by the caller
+func (c *lruCache) set(key string, item *cacheItem) {
+	now := c.now()
+
+	switch {
+	case item.negative() && c.opts.NegativeTTL < 1:
+		c.delete(key)
+		return
+	case item.negative():
+		item.cachedUntil = now.Add(c.opts.NegativeTTL)
+	case c.opts.TTL > 0:
+		item.cachedUntil = now.Add(c.opts.TTL)
+	}
+
+	item.key = key
+
+	if elem, found := c.items[key]; found {
+		elem.Value = item
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(item)
+
+	for c.order.Len() > c.opts.Size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheItem).key)
+		c.evictions++
+	}
+}
+
+// remove invalidates the specified key, including the results of its in flight reads
+func (c *lruCache) remove(key string) {
+	c.Lock()
+	defer c.Unlock()
+
+	if f, found := c.inflight[key]; found {
+		f.gen++
+	}
+
+	c.delete(key)
+}
+
+// delete deletes the item of the specified key if any, the cache must be locked by the caller
+func (c *lruCache) delete(key string) {
+	if elem, found := c.items[key]; found {
+		c.order.Remove(elem)
+		delete(c.items, key)
+	}
+}
+
+// clear invalidates all the keys
+func (c *lruCache) clear() {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, f := range c.inflight {
+		f.gen++
+	}
+
+	c.items = map[string]*list.Element{}
+	c.order.Init()
+}
+
+// stats returns the cache statistics
+func (c *lruCache) stats() CacheStats {
+	c.Lock()
+	defer c.Unlock()
+
+	return CacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Size:      c.order.Len(),
+	}
+}
+
+// copyBytes returns a copy of the specified bytes, so the cached values can't be mutated by the callers
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+
+	return append([]byte{}, b...)
+}
@@ -0,0 +1,175 @@
+package goukv
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCachedReadThrough(t *testing.T) {
+	backend := newMemoryProvider()
+	db := NewCached(backend, CacheOpts{Size: 2})
+
+	db.Put(&Entry{Key: []byte("k"), Value: []byte("v1")})
+	db.Get([]byte("k"))
+
+	// written directly to the backend, so the cached value is still served
+	backend.Put(&Entry{Key: []byte("k"), Value: []byte("v2")})
+
+	if val, err := db.Get([]byte("k")); err != nil || string(val) != "v1" {
+		t.Errorf("expected the cached (v1), found (%s, %v)", string(val), err)
+	}
+
+	// written through the cache, so the key is invalidated
+	db.Put(&Entry{Key: []byte("k"), Value: []byte("v3")})
+
+	if val, err := db.Get([]byte("k")); err != nil || string(val) != "v3" {
+		t.Errorf("expected (v3), found (%s, %v)", string(val), err)
+	}
+
+	if stats := db.CacheStats(); stats.Hits != 1 || stats.Misses != 2 {
+		t.Errorf("expected (1) hit and (2) misses, found %+v", stats)
+	}
+}
+
+// racyProvider a provider that calls onGet after reading a key and before returning it
+type racyProvider struct {
+	*memoryProvider
+	onGet func()
+}
+
+func (r *racyProvider) Get(k []byte) ([]byte, error) {
+	val, err := r.memoryProvider.Get(k)
+	if r.onGet != nil {
+		r.onGet()
+	}
+
+	return val, err
+}
+
+func TestCachedReadThroughRace(t *testing.T) {
+	backend := &racyProvider{memoryProvider: newMemoryProvider()}
+	db := NewCached(backend, CacheOpts{})
+
+	backend.Put(&Entry{Key: []byte("k"), Value: []byte("v1")})
+
+	// the key is written through the cache while its old value is being read
+	backend.onGet = func() {
+		backend.onGet = nil
+		db.Put(&Entry{Key: []byte("k"), Value: []byte("v2")})
+	}
+
+	if val, err := db.Get([]byte("k")); err != nil || string(val) != "v1" {
+		t.Errorf("expected (v1), found (%s, %v)", string(val), err)
+	}
+
+	if val, err := db.Get([]byte("k")); err != nil || string(val) != "v2" {
+		t.Errorf("expected the stale (v1) not to be cached, found (%s, %v)", string(val), err)
+	}
+}
+
+func TestCachedExpiry(t *testing.T) {
+	clock := newFakeClock()
+	backend := newMemoryProvider()
+	backend.now = clock.Now
+
+	db := NewCached(backend, CacheOpts{})
+	db.cache.now = clock.Now
+
+	db.Put(&Entry{Key: []byte("k"), Value: []byte("v"), TTL: time.Minute})
+
+	if _, err := db.Get([]byte("k")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.TTL([]byte("k")); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(time.Minute)
+
+	if _, err := db.Get([]byte("k")); err != ErrKeyExpired {
+		t.Errorf("expected (%v), found (%v)", ErrKeyExpired, err)
+	}
+}
+
+func TestCachedNegative(t *testing.T) {
+	clock := newFakeClock()
+	backend := newMemoryProvider()
+	backend.now = clock.Now
+
+	db := NewCached(backend, CacheOpts{NegativeTTL: time.Minute})
+	db.cache.now = clock.Now
+
+	if _, err := db.Get([]byte("k")); err != ErrKeyNotFound {
+		t.Fatalf("expected (%v), found (%v)", ErrKeyNotFound, err)
+	}
+
+	backend.Put(&Entry{Key: []byte("k"), Value: []byte("v")})
+
+	if _, err := db.Get([]byte("k")); err != ErrKeyNotFound {
+		t.Errorf("expected the cached (%v), found (%v)", ErrKeyNotFound, err)
+	}
+
+	clock.Advance(time.Minute)
+
+	if val, err := db.Get([]byte("k")); err != nil || string(val) != "v" {
+		t.Errorf("expected (v), found (%s, %v)", string(val), err)
+	}
+}
+
+func TestCachedEviction(t *testing.T) {
+	db := NewCached(newMemoryProvider(), CacheOpts{Size: 2})
+
+	db.Batch([]*Entry{
+		{Key: []byte("k1"), Value: []byte("v1")},
+		{Key: []byte("k2"), Value: []byte("v2")},
+		{Key: []byte("k3"), Value: []byte("v3")},
+	})
+
+	for _, k := range []string{"k1", "k2", "k1", "k3"} {
+		db.Get([]byte(k))
+	}
+
+	stats := db.CacheStats()
+	if stats.Size != 2 || stats.Evictions != 1 {
+		t.Errorf("expected (2) cached keys and (1) eviction, found %+v", stats)
+	}
+
+	// k2 is the least recently used key
+	db.Get([]byte("k1"))
+	if hits := db.CacheStats().Hits; hits != stats.Hits+1 {
+		t.Errorf("expected k1 to be kept")
+	}
+
+	if Stats(db)["cache_size"] != 2 {
+		t.Errorf("expected the cache stats to be exposed, found %v", Stats(db))
+	}
+}
+
+// vanishingProvider a provider whose keys are deleted right after being read
+type vanishingProvider struct {
+	*memoryProvider
+}
+
+func (v *vanishingProvider) Get(k []byte) ([]byte, error) {
+	val, err := v.memoryProvider.Get(k)
+	v.memoryProvider.Delete(k)
+
+	return val, err
+}
+
+func TestCachedVanishedKey(t *testing.T) {
+	backend := &vanishingProvider{memoryProvider: newMemoryProvider()}
+	db := NewCached(backend, CacheOpts{NegativeTTL: time.Hour})
+
+	backend.Put(&Entry{Key: []byte("k"), Value: []byte("v")})
+
+	if val, err := db.Get([]byte("k")); err != nil || string(val) != "v" {
+		t.Fatalf("expected (v), found (%s, %v)", string(val), err)
+	}
+
+	// the key has been deleted before its ttl is read, so it is cached as not found
+	if _, err := db.Get([]byte("k")); err != ErrKeyNotFound {
+		t.Errorf("expected (%v), found (%v)", ErrKeyNotFound, err)
+	}
+}
@@ -0,0 +1,79 @@
+// Package codecs provides the goukv.Codec implementations that depend on third party packages,
+// the stdlib based ones are goukv.JSONCodec and goukv.GobCodec.
+package codecs
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/alash3al/goukv"
+	"github.com/vmihailenco/msgpack/v4"
+	"google.golang.org/protobuf/proto"
+)
+
+// the available codecs
+var (
+	Msgpack  goukv.Codec = msgpackCodec{}
+	Protobuf goukv.Codec = protobufCodec{}
+)
+
+// error related variables
+var (
+	ErrNotProtoMessage = errors.New("the value isn't a protobuf message")
+)
+
+// msgpackCodec implements goukv.Codec using msgpack
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+// protobufCodec implements goukv.Codec using protobuf, the typed store value
+// could be either a message or a pointer to a message, i.e: `goukv.Typed[*pb.User]`
+type protobufCodec struct{}
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, err := message(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return proto.Marshal(msg)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, err := message(v)
+	if err != nil {
+		return err
+	}
+
+	return proto.Unmarshal(data, msg)
+}
+
+// message returns the message of the specified value, a nil message pointer is allocated
+func message(v interface{}) (proto.Message, error) {
+	if msg, ok := v.(proto.Message); ok {
+		return msg, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Ptr {
+		return nil, ErrNotProtoMessage
+	}
+
+	if rv.Elem().IsNil() {
+		rv.Elem().Set(reflect.New(rv.Elem().Type().Elem()))
+	}
+
+	msg, ok := rv.Elem().Interface().(proto.Message)
+	if !ok {
+		return nil, ErrNotProtoMessage
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,66 @@
+package codecs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/internal/testutil"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type user struct {
+	Name string
+	Age  int
+}
+
+func TestCodecs(t *testing.T) {
+	for name, codec := range map[string]goukv.Codec{"json": goukv.JSONCodec, "gob": goukv.GobCodec, "msgpack": Msgpack} {
+		testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+			users := goukv.NewTyped[user](db, codec)
+
+			if err := users.Put([]byte("u1"), user{Name: "someone", Age: 30}, time.Hour); err != nil {
+				t.Fatal(err)
+			}
+			users.Put([]byte("u2"), user{Name: "other", Age: 40}, 0)
+
+			u, err := users.Get([]byte("u1"))
+			if err != nil || u.Name != "someone" || u.Age != 30 {
+				t.Errorf("(%s) unexpected user (%+v, %v)", name, u, err)
+			}
+
+			if _, err := users.Get([]byte("not-found")); err != goukv.ErrKeyNotFound {
+				t.Errorf("(%s) expected (%v), found (%v)", name, goukv.ErrKeyNotFound, err)
+			}
+
+			ages := 0
+			users.Scan(goukv.ScanOpts{Prefix: []byte("u")}, func(k []byte, u user) bool {
+				ages += u.Age
+				return true
+			})
+
+			if ages != 70 {
+				t.Errorf("(%s) expected (70), found (%d)", name, ages)
+			}
+		})
+	}
+}
+
+func TestProtobuf(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		messages := goukv.NewTyped[*wrapperspb.StringValue](db, Protobuf)
+
+		if err := messages.Put([]byte("k"), wrapperspb.String("v"), 0); err != nil {
+			t.Fatal(err)
+		}
+
+		msg, err := messages.Get([]byte("k"))
+		if err != nil || msg.GetValue() != "v" {
+			t.Errorf("expected (v), found (%v, %v)", msg, err)
+		}
+
+		if err := goukv.NewTyped[string](db, Protobuf).Put([]byte("k"), "v", 0); err != ErrNotProtoMessage {
+			t.Errorf("expected (%v), found (%v)", ErrNotProtoMessage, err)
+		}
+	})
+}
@@ -0,0 +1,396 @@
+// Package compression compresses the values of goukv providers using snappy, zstd or gzip.
+package compression
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"time"
+
+	"github.com/alash3al/goukv"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/dict"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// the available codecs, each one is identified by its tag in the values header
+const (
+	None   Codec = 'n'
+	Snappy Codec = 's'
+	Zstd   Codec = 'z'
+	Gzip   Codec = 'g'
+)
+
+// the payload encodings
+const (
+	encodingBinary = 'b'
+	encodingText   = 't'
+)
+
+const (
+	defaultMinSize = 128
+)
+
+// magic the prefix of the values written by the wrapper, the values without it are returned as is,
+// so the values written before enabling the compression remain readable
+var magic = []byte("\x1eGKZ")
+
+// error related variables
+var (
+	ErrUnknownCodec     = errors.New("the specified codec isn't supported")
+	ErrMalformedPayload = errors.New("the compressed value is malformed")
+)
+
+// Codec a compression algorithm
+type Codec byte
+
+// Opts the options of the compression layer
+type Opts struct {
+	// Codec the codec used to compress the new values, Zstd by default
+	Codec Codec
+
+	// MinSize the values smaller than it are stored as is, 128 bytes by default
+	MinSize int
+
+	// ZstdDictionaries the zstd dictionaries (see TrainZstdDictionary), the first one is used to compress
+	// the new values, while all of them are used to decompress the values, so a new dictionary could be
+	// used while keeping the old ones readable
+	ZstdDictionaries [][]byte
+
+	// TextSafe encodes the compressed values using base64, so they could be stored in text columns,
+	// i.e: postgres' `_v`
+	TextSafe bool
+}
+
+// Provider a provider that compresses the values before passing them to the provider it wraps
+// and decompresses them on the way back, the values that don't get smaller are stored as is.
+// the compressed value layout is: magic(4) | codec(1) | encoding(1) | payload
+type Provider struct {
+	provider goukv.Provider
+	opts     Opts
+	encoder  *zstd.Encoder
+	decoder  *zstd.Decoder
+
+	// bound whether it is a copy returned by WithContext, the copies share the zstd encoder
+	// and decoder of the provider they are bound from, so they don't release them on Close
+	bound bool
+}
+
+// Wrap wraps the specified provider using the specified options
+func Wrap(p goukv.Provider, opts Opts) (*Provider, error) {
+	if opts.Codec == 0 {
+		opts.Codec = Zstd
+	}
+
+	if opts.MinSize < 1 {
+		opts.MinSize = defaultMinSize
+	}
+
+	switch opts.Codec {
+	case None, Snappy, Zstd, Gzip:
+	default:
+		return nil, ErrUnknownCodec
+	}
+
+	encoderOpts := []zstd.EOption{}
+	if len(opts.ZstdDictionaries) > 0 {
+		encoderOpts = append(encoderOpts, zstd.WithEncoderDict(opts.ZstdDictionaries[0]))
+	}
+
+	encoder, err := zstd.NewWriter(nil, encoderOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderDicts(opts.ZstdDictionaries...))
+	if err != nil {
+		encoder.Close()
+		return nil, err
+	}
+
+	return &Provider{
+		provider: p,
+		opts:     opts,
+		encoder:  encoder,
+		decoder:  decoder,
+	}, nil
+}
+
+// TrainZstdDictionary builds a zstd dictionary of at most the specified size from the specified
+// sample values, the samples should be representative of the values to be compressed
+func TrainZstdDictionary(samples [][]byte, size int) ([]byte, error) {
+	return dict.BuildZstdDict(samples, dict.Options{
+		MaxDictSize: size,
+		HashBytes:   6,
+	})
+}
+
+// Unwrap implements goukv.Unwrapper
+func (p Provider) Unwrap() goukv.Provider {
+	return p.provider
+}
+
+// WithContext implements goukv.ContextBinder
+func (p Provider) WithContext(ctx context.Context) goukv.Provider {
+	p.provider = goukv.WithContext(ctx, p.provider)
+	p.bound = true
+
+	return &p
+}
+
+// Open implements goukv.Open, the opened provider gets its own zstd encoder and decoder
+func (p Provider) Open(dsn *goukv.DSN) (goukv.Provider, error) {
+	db, err := p.provider.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := Wrap(db, p.opts)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return wrapped, nil
+}
+
+// Put implements goukv.Put
+func (p Provider) Put(e *goukv.Entry) error {
+	val, err := p.Compress(e.Value)
+	if err != nil {
+		return err
+	}
+
+	return p.provider.Put(&goukv.Entry{Key: e.Key, Value: val, TTL: e.TTL})
+}
+
+// Batch implements goukv.Batch
+func (p Provider) Batch(entries []*goukv.Entry) error {
+	compressed := make([]*goukv.Entry, 0, len(entries))
+
+	for _, entry := range entries {
+		if entry.Value == nil {
+			compressed = append(compressed, entry)
+			continue
+		}
+
+		val, err := p.Compress(entry.Value)
+		if err != nil {
+			return err
+		}
+
+		compressed = append(compressed, &goukv.Entry{Key: entry.Key, Value: val, TTL: entry.TTL})
+	}
+
+	return p.provider.Batch(compressed)
+}
+
+// Get implements goukv.Get
+func (p Provider) Get(k []byte) ([]byte, error) {
+	val, err := p.provider.Get(k)
+	if err != nil || val == nil {
+		return val, err
+	}
+
+	return p.Decompress(val)
+}
+
+// TTL implements goukv.TTL
+func (p Provider) TTL(k []byte) (*time.Time, error) {
+	return p.provider.TTL(k)
+}
+
+// Delete implements goukv.Delete
+func (p Provider) Delete(k []byte) error {
+	return p.provider.Delete(k)
+}
+
+// CompareAndSwap implements goukv.CompareAndSwapper, the same value could be stored differently (i.e: by another
+// codec), so the current value is decompressed and compared to the specified old one, then it is swapped only if
+// the stored value hasn't changed meanwhile
+func (p Provider) CompareAndSwap(e *goukv.Entry, old []byte) (bool, error) {
+	val := e.Value
+	if val != nil {
+		compressed, err := p.Compress(val)
+		if err != nil {
+			return false, err
+		}
+
+		val = compressed
+	}
+
+	entry := &goukv.Entry{Key: e.Key, Value: val, TTL: e.TTL}
+
+	if old == nil {
+		return goukv.CompareAndSwap(p.provider, entry, nil)
+	}
+
+	stored, err := p.provider.Get(e.Key)
+	if err == goukv.ErrKeyNotFound || err == goukv.ErrKeyExpired || (err == nil && stored == nil) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	current, err := p.Decompress(stored)
+	if err != nil {
+		return false, err
+	}
+
+	if !bytes.Equal(current, old) {
+		return false, nil
+	}
+
+	return goukv.CompareAndSwap(p.provider, entry, stored)
+}
+
+// Scan implements goukv.Scan, the scan stops at the first value that can't be decompressed
+func (p Provider) Scan(opts goukv.ScanOpts) error {
+	if opts.Scanner == nil {
+		return p.provider.Scan(opts)
+	}
+
+	var decompressErr error
+	scanner := opts.Scanner
+
+	opts.Scanner = func(k, v []byte) bool {
+		val, err := p.Decompress(v)
+		if err != nil {
+			decompressErr = err
+			return false
+		}
+
+		return scanner(k, val)
+	}
+
+	if err := p.provider.Scan(opts); err != nil {
+		return err
+	}
+
+	return decompressErr
+}
+
+// Close implements goukv.Close, it releases the zstd encoder and decoder too unless
+// it is bound using WithContext, then they are released by the provider it is bound from
+func (p Provider) Close() error {
+	if !p.bound {
+		p.encoder.Close()
+		p.decoder.Close()
+	}
+
+	return p.provider.Close()
+}
+
+// Compress compresses the specified value using the configured codec
+func (p Provider) Compress(val []byte) ([]byte, error) {
+	if len(val) < p.opts.MinSize || p.opts.Codec == None {
+		return p.raw(val), nil
+	}
+
+	var payload []byte
+
+	switch p.opts.Codec {
+	case Snappy:
+		payload = snappy.Encode(nil, val)
+	case Zstd:
+		payload = p.encoder.EncodeAll(val, nil)
+	case Gzip:
+		var buf bytes.Buffer
+
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(val); err != nil {
+			return nil, err
+		}
+
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+
+		payload = buf.Bytes()
+	}
+
+	framed := p.frame(p.opts.Codec, payload)
+	if len(framed) >= len(val) {
+		return p.raw(val), nil
+	}
+
+	return framed, nil
+}
+
+// Decompress decompresses the specified value whatever the codec it was compressed by
+func (p Provider) Decompress(val []byte) ([]byte, error) {
+	if !bytes.HasPrefix(val, magic) {
+		return val, nil
+	}
+
+	if len(val) < len(magic)+2 {
+		return nil, ErrMalformedPayload
+	}
+
+	codec, encoding, payload := Codec(val[len(magic)]), val[len(magic)+1], val[len(magic)+2:]
+
+	switch encoding {
+	case encodingBinary:
+	case encodingText:
+		decoded, err := base64.StdEncoding.DecodeString(string(payload))
+		if err != nil {
+			return nil, ErrMalformedPayload
+		}
+
+		payload = decoded
+	default:
+		return nil, ErrMalformedPayload
+	}
+
+	switch codec {
+	case None:
+		return payload, nil
+	case Snappy:
+		return snappy.Decode(nil, payload)
+	case Zstd:
+		return p.decoder.DecodeAll(payload, nil)
+	case Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		return ioutil.ReadAll(r)
+	}
+
+	return nil, ErrUnknownCodec
+}
+
+// raw returns the stored form of the specified uncompressed value, it is stored as is
+// unless it starts with the magic, so it isn't confused with a compressed one
+func (p Provider) raw(val []byte) []byte {
+	if !bytes.HasPrefix(val, magic) {
+		return val
+	}
+
+	return p.frame(None, val)
+}
+
+// frame prepends the header to the specified payload
+func (p Provider) frame(codec Codec, payload []byte) []byte {
+	encoding := byte(encodingBinary)
+	if p.opts.TextSafe {
+		encoding = encodingText
+	}
+
+	framed := make([]byte, 0, len(magic)+2+len(payload))
+	framed = append(framed, magic...)
+	framed = append(framed, byte(codec), encoding)
+
+	if p.opts.TextSafe {
+		return append(framed, base64.StdEncoding.EncodeToString(payload)...)
+	}
+
+	return append(framed, payload...)
+}
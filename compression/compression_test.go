@@ -0,0 +1,186 @@
+package compression
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/internal/testutil"
+	"github.com/alash3al/goukv/providers/leveldb"
+)
+
+func blob(i int) []byte {
+	return []byte(fmt.Sprintf(`{"id": %d, "name": "user %d", "email": "user%d@example.com", "tags": ["a", "b", "c"], "bio": "%s"}`,
+		i, i, i, bytes.Repeat([]byte("lorem ipsum "), 20)))
+}
+
+func TestCodecs(t *testing.T) {
+	for _, codec := range []Codec{Snappy, Zstd, Gzip} {
+		testutil.OpenDBAndDo(t, func(raw goukv.Provider) {
+			db, err := Wrap(raw, Opts{Codec: codec, TextSafe: codec == Gzip})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// written before enabling the compression
+			raw.Put(&goukv.Entry{Key: []byte("legacy"), Value: []byte("plain")})
+
+			db.Batch([]*goukv.Entry{
+				{Key: []byte("big"), Value: blob(1)},
+				{Key: []byte("small"), Value: []byte("v")},
+				{Key: []byte("tricky"), Value: append(append([]byte{}, magic...), 'x')},
+			})
+
+			stored, _ := raw.Get([]byte("big"))
+			if len(stored) >= len(blob(1)) || stored[len(magic)] != byte(codec) {
+				t.Errorf("expected (%c) to compress the value, found (%d) bytes", codec, len(stored))
+			}
+
+			if codec == Gzip && (!utf8.Valid(stored) || bytes.IndexByte(stored, 0) >= 0) {
+				t.Error("expected a text safe value")
+			}
+
+			if stored, _ := raw.Get([]byte("small")); string(stored) != "v" {
+				t.Errorf("expected the small value to be stored as is, found (%q)", stored)
+			}
+
+			found := map[string][]byte{}
+			db.Scan(goukv.ScanOpts{Scanner: func(k, v []byte) bool {
+				found[string(k)] = append([]byte{}, v...)
+				return true
+			}})
+
+			expected := map[string][]byte{
+				"big":    blob(1),
+				"small":  []byte("v"),
+				"legacy": []byte("plain"),
+				"tricky": append(append([]byte{}, magic...), 'x'),
+			}
+
+			for k, v := range expected {
+				if !bytes.Equal(found[k], v) {
+					t.Errorf("(%c) expected (%s) to be (%q), found (%q)", codec, k, v, found[k])
+				}
+			}
+		})
+	}
+}
+
+func TestZstdDictionaries(t *testing.T) {
+	samples := [][]byte{}
+	for i := 0; i < 200; i++ {
+		samples = append(samples, blob(i))
+	}
+
+	oldDict, err := TrainZstdDictionary(samples[:100], 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newDict, err := TrainZstdDictionary(samples[100:], 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.OpenDBAndDo(t, func(raw goukv.Provider) {
+		old, _ := Wrap(raw, Opts{ZstdDictionaries: [][]byte{oldDict}})
+		old.Put(&goukv.Entry{Key: []byte("k1"), Value: blob(1000)})
+
+		db, err := Wrap(raw, Opts{ZstdDictionaries: [][]byte{newDict, oldDict}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		db.Put(&goukv.Entry{Key: []byte("k2"), Value: blob(2000)})
+
+		for k, v := range map[string][]byte{"k1": blob(1000), "k2": blob(2000)} {
+			if val, err := db.Get([]byte(k)); err != nil || !bytes.Equal(val, v) {
+				t.Errorf("unexpected (%s) value (%q, %v)", k, val, err)
+			}
+		}
+	})
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(raw goukv.Provider) {
+		snappy, _ := Wrap(raw, Opts{Codec: Snappy})
+		zstd, _ := Wrap(raw, Opts{Codec: Zstd})
+
+		if swapped, err := snappy.CompareAndSwap(&goukv.Entry{Key: []byte("k"), Value: blob(1)}, nil); err != nil || !swapped {
+			t.Fatalf("expected the absent key to be swapped, found (%v, %v)", swapped, err)
+		}
+
+		// the value is compared once decompressed, whatever the codec it was stored by
+		if swapped, err := zstd.CompareAndSwap(&goukv.Entry{Key: []byte("k"), Value: blob(2)}, blob(1)); err != nil || !swapped {
+			t.Fatalf("expected the value to be swapped, found (%v, %v)", swapped, err)
+		}
+
+		if swapped, err := zstd.CompareAndSwap(&goukv.Entry{Key: []byte("k"), Value: blob(3)}, blob(1)); err != nil || swapped {
+			t.Errorf("expected the stale old value not to be swapped, found (%v, %v)", swapped, err)
+		}
+
+		if val, err := snappy.Get([]byte("k")); err != nil || !bytes.Equal(val, blob(2)) {
+			t.Errorf("expected the swapped value, found (%s, %v)", string(val), err)
+		}
+	})
+}
+
+func TestOpenAndClose(t *testing.T) {
+	dir := t.TempDir()
+	dsn, _ := goukv.NewDSN("leveldb://" + dir + "/a")
+
+	db, err := Wrap(leveldb.Provider{}, Opts{Codec: Zstd})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := db.Open(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dsn, _ = goukv.NewDSN("leveldb://" + dir + "/b")
+
+	second, err := first.Open(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+
+	// the opened providers don't share the zstd encoder and decoder, so closing one doesn't break the other
+	if err := first.Close(); err != nil {
+		t.Error(err)
+	}
+
+	if err := second.Put(&goukv.Entry{Key: []byte("k"), Value: blob(1)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if val, err := second.Get([]byte("k")); err != nil || !bytes.Equal(val, blob(1)) {
+		t.Errorf("expected the value to be decompressed, found (%s, %v)", string(val), err)
+	}
+}
+
+func TestBoundClose(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(p goukv.Provider) {
+		db, err := Wrap(p, Opts{Codec: Zstd})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the bound copy shares the zstd encoder and decoder, so closing it doesn't release them
+		db.WithContext(context.Background()).Close()
+
+		compressed, err := db.Compress(blob(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if val, err := db.Decompress(compressed); err != nil || !bytes.Equal(val, blob(1)) {
+			t.Errorf("expected the value to be decompressed, found (%s, %v)", string(val), err)
+		}
+	})
+}
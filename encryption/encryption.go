@@ -0,0 +1,334 @@
+// Package encryption encrypts the values of goukv providers at rest using AES-GCM.
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/alash3al/goukv"
+)
+
+const (
+	version        = 1
+	maxKeyIDLength = 255
+)
+
+// magic the prefix of the encrypted values
+var magic = []byte("GKE")
+
+// textMagic the prefix of the text safe encrypted values, it is followed by the base64 of the encrypted value,
+// it can't be confused with magic as the version never matches its next byte
+var textMagic = []byte("GKE:")
+
+// error related variables
+var (
+	ErrKeyIDNotFound      = errors.New("the requested key id isn't found")
+	ErrKeyIDAlreadyExists = errors.New("the specified key id already exists")
+	ErrInvalidKeyID       = errors.New("the key id must be 1 to 255 bytes")
+	ErrNotEncrypted       = errors.New("the value isn't encrypted")
+	ErrInvalidCiphertext  = errors.New("the encrypted value is malformed")
+)
+
+// Opts the options of the encryption layer
+type Opts struct {
+	// AllowPlaintext returns the values that aren't encrypted as is instead of failing with ErrNotEncrypted,
+	// i.e: while migrating an existing store using Reencrypt
+	AllowPlaintext bool
+
+	// TextSafe encodes the encrypted values using base64 (prefixed by `GKE:`), so they could be stored in text
+	// columns, i.e: postgres' `_v`. both forms are always decrypted, and Reencrypt rewrites the values of the other form
+	TextSafe bool
+}
+
+// Provider a provider that encrypts the values before passing them to the provider it wraps
+// and decrypts them on the way back, the keys are stored in plaintext.
+// the encrypted value layout is: magic(3) | version(1) | key id length(1) | key id | nonce | ciphertext,
+// the entry key is used as the additional data, so a value can't be moved to another key.
+type Provider struct {
+	provider goukv.Provider
+	keys     KeyProvider
+	opts     Opts
+	aeads    *sync.Map
+}
+
+// Wrap wraps the specified provider using the specified key provider
+func Wrap(p goukv.Provider, keys KeyProvider, opts Opts) *Provider {
+	return &Provider{
+		provider: p,
+		keys:     keys,
+		opts:     opts,
+		aeads:    &sync.Map{},
+	}
+}
+
+// Unwrap implements goukv.Unwrapper
+func (p Provider) Unwrap() goukv.Provider {
+	return p.provider
+}
+
+// WithContext implements goukv.ContextBinder
+func (p Provider) WithContext(ctx context.Context) goukv.Provider {
+	p.provider = goukv.WithContext(ctx, p.provider)
+
+	return &p
+}
+
+// Open implements goukv.Open
+func (p Provider) Open(dsn *goukv.DSN) (goukv.Provider, error) {
+	db, err := p.provider.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return Wrap(db, p.keys, p.opts), nil
+}
+
+// Put implements goukv.Put
+func (p Provider) Put(e *goukv.Entry) error {
+	val, err := p.Encrypt(e.Key, e.Value)
+	if err != nil {
+		return err
+	}
+
+	return p.provider.Put(&goukv.Entry{Key: e.Key, Value: val, TTL: e.TTL})
+}
+
+// Batch implements goukv.Batch
+func (p Provider) Batch(entries []*goukv.Entry) error {
+	encrypted := make([]*goukv.Entry, 0, len(entries))
+
+	for _, entry := range entries {
+		if entry.Value == nil {
+			encrypted = append(encrypted, entry)
+			continue
+		}
+
+		val, err := p.Encrypt(entry.Key, entry.Value)
+		if err != nil {
+			return err
+		}
+
+		encrypted = append(encrypted, &goukv.Entry{Key: entry.Key, Value: val, TTL: entry.TTL})
+	}
+
+	return p.provider.Batch(encrypted)
+}
+
+// Get implements goukv.Get
+func (p Provider) Get(k []byte) ([]byte, error) {
+	val, err := p.provider.Get(k)
+	if err != nil || val == nil {
+		return val, err
+	}
+
+	return p.Decrypt(k, val)
+}
+
+// TTL implements goukv.TTL
+func (p Provider) TTL(k []byte) (*time.Time, error) {
+	return p.provider.TTL(k)
+}
+
+// Delete implements goukv.Delete
+func (p Provider) Delete(k []byte) error {
+	return p.provider.Delete(k)
+}
+
+// CompareAndSwap implements goukv.CompareAndSwapper, the same plaintext is encrypted differently each time,
+// so the current value is decrypted and compared to the specified old one, then it is swapped only if the
+// stored ciphertext hasn't changed meanwhile
+func (p Provider) CompareAndSwap(e *goukv.Entry, old []byte) (bool, error) {
+	val := e.Value
+	if val != nil {
+		encrypted, err := p.Encrypt(e.Key, val)
+		if err != nil {
+			return false, err
+		}
+
+		val = encrypted
+	}
+
+	entry := &goukv.Entry{Key: e.Key, Value: val, TTL: e.TTL}
+
+	if old == nil {
+		return goukv.CompareAndSwap(p.provider, entry, nil)
+	}
+
+	stored, err := p.provider.Get(e.Key)
+	if err == goukv.ErrKeyNotFound || err == goukv.ErrKeyExpired || (err == nil && stored == nil) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	current, err := p.Decrypt(e.Key, stored)
+	if err != nil {
+		return false, err
+	}
+
+	if !bytes.Equal(current, old) {
+		return false, nil
+	}
+
+	return goukv.CompareAndSwap(p.provider, entry, stored)
+}
+
+// Scan implements goukv.Scan, the scan stops at the first value that can't be decrypted
+func (p Provider) Scan(opts goukv.ScanOpts) error {
+	if opts.Scanner == nil {
+		return p.provider.Scan(opts)
+	}
+
+	var decryptErr error
+	scanner := opts.Scanner
+
+	opts.Scanner = func(k, v []byte) bool {
+		val, err := p.Decrypt(k, v)
+		if err != nil {
+			decryptErr = err
+			return false
+		}
+
+		return scanner(k, val)
+	}
+
+	if err := p.provider.Scan(opts); err != nil {
+		return err
+	}
+
+	return decryptErr
+}
+
+// Close implements goukv.Close
+func (p Provider) Close() error {
+	return p.provider.Close()
+}
+
+// Encrypt encrypts the specified value of the specified key using the current key
+func (p Provider) Encrypt(k, val []byte) ([]byte, error) {
+	id, key, err := p.keys.CurrentKey()
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := p.aead(id, key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, len(magic)+2+len(id)+aead.NonceSize())
+	header = append(header, magic...)
+	header = append(header, version, byte(len(id)))
+	header = append(header, id...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := aead.Seal(append(header, nonce...), nonce, val, k)
+	if !p.opts.TextSafe {
+		return sealed, nil
+	}
+
+	encoded := make([]byte, len(textMagic), len(textMagic)+base64.StdEncoding.EncodedLen(len(sealed)))
+	copy(encoded, textMagic)
+
+	return append(encoded, base64.StdEncoding.EncodeToString(sealed)...), nil
+}
+
+// Decrypt decrypts the specified value of the specified key using the key it was encrypted by
+func (p Provider) Decrypt(k, val []byte) ([]byte, error) {
+	id, body, _, err := parse(val)
+	if err == ErrNotEncrypted && p.opts.AllowPlaintext {
+		return val, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := p.keys.Key(id)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := p.aead(id, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	return aead.Open(nil, body[:aead.NonceSize()], body[aead.NonceSize():], k)
+}
+
+// aead returns the cipher of the specified key id
+func (p Provider) aead(id string, key []byte) (cipher.AEAD, error) {
+	if aead, found := p.aeads.Load(id); found {
+		return aead.(cipher.AEAD), nil
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	p.aeads.Store(id, aead)
+
+	return aead, nil
+}
+
+// KeyID returns the id of the key the specified value is encrypted by
+func KeyID(val []byte) (string, error) {
+	id, _, _, err := parse(val)
+
+	return id, err
+}
+
+// parse splits the specified encrypted value into its key id and nonce+ciphertext,
+// and reports whether it is text safe
+func parse(val []byte) (string, []byte, bool, error) {
+	if bytes.HasPrefix(val, textMagic) {
+		decoded, err := base64.StdEncoding.DecodeString(string(val[len(textMagic):]))
+		if err != nil {
+			return "", nil, true, ErrInvalidCiphertext
+		}
+
+		id, body, _, err := parse(decoded)
+		if err == ErrNotEncrypted {
+			err = ErrInvalidCiphertext
+		}
+
+		return id, body, true, err
+	}
+
+	if !bytes.HasPrefix(val, magic) || len(val) < len(magic)+2 || val[len(magic)] != version {
+		return "", nil, false, ErrNotEncrypted
+	}
+
+	val = val[len(magic)+1:]
+	idLen := int(val[0])
+
+	if idLen < 1 || len(val) < 1+idLen {
+		return "", nil, false, ErrInvalidCiphertext
+	}
+
+	return string(val[1 : 1+idLen]), val[1+idLen:], false, nil
+}
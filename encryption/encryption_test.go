@@ -0,0 +1,179 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/internal/testutil"
+)
+
+func TestPutGet(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(raw goukv.Provider) {
+		keys, _ := NewKeyring("k1", bytes.Repeat([]byte("a"), 32))
+		db := Wrap(raw, keys, Opts{})
+
+		if err := db.Put(&goukv.Entry{Key: []byte("ssn"), Value: []byte("123-45-6789")}); err != nil {
+			t.Fatal(err)
+		}
+
+		stored, _ := raw.Get([]byte("ssn"))
+		if bytes.Contains(stored, []byte("123-45-6789")) {
+			t.Error("expected the stored value to be encrypted")
+		}
+
+		if id, err := KeyID(stored); err != nil || id != "k1" {
+			t.Errorf("expected (k1), found (%s, %v)", id, err)
+		}
+
+		val, err := db.Get([]byte("ssn"))
+		if err != nil || string(val) != "123-45-6789" {
+			t.Errorf("expected (123-45-6789), found (%s, %v)", string(val), err)
+		}
+
+		// the value is bound to its key
+		raw.Put(&goukv.Entry{Key: []byte("other"), Value: stored})
+		if _, err := db.Get([]byte("other")); err == nil {
+			t.Error("expected a moved value to fail decrypting")
+		}
+	})
+}
+
+func TestRotateAndReencrypt(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(raw goukv.Provider) {
+		raw.Put(&goukv.Entry{Key: []byte("plain"), Value: []byte("legacy"), TTL: time.Hour})
+
+		keys, _ := NewKeyring("k1", bytes.Repeat([]byte("a"), 16))
+		db := Wrap(raw, keys, Opts{AllowPlaintext: true})
+
+		db.Batch([]*goukv.Entry{
+			{Key: []byte("a"), Value: []byte("v1")},
+			{Key: []byte("b"), Value: []byte("v2")},
+			{Key: []byte("c"), Value: []byte("v3")},
+		})
+
+		if err := keys.Rotate("k2", bytes.Repeat([]byte("b"), 32)); err != nil {
+			t.Fatal(err)
+		}
+
+		db.Put(&goukv.Entry{Key: []byte("d"), Value: []byte("v4")})
+
+		found := map[string]string{}
+		db.Scan(goukv.ScanOpts{Scanner: func(k, v []byte) bool {
+			found[string(k)] = string(v)
+			return true
+		}})
+
+		if len(found) != 5 || found["a"] != "v1" || found["d"] != "v4" || found["plain"] != "legacy" {
+			t.Errorf("unexpected scan result %v", found)
+		}
+
+		n, err := db.Reencrypt(context.Background(), ReencryptOpts{BatchSize: 2})
+		if err != nil || n != 4 {
+			t.Fatalf("expected (4) rewritten values, found (%d, %v)", n, err)
+		}
+
+		raw.Scan(goukv.ScanOpts{Scanner: func(k, v []byte) bool {
+			if id, _ := KeyID(v); id != "k2" {
+				t.Errorf("expected (%s) to be encrypted by k2, found (%s)", string(k), id)
+			}
+			return true
+		}})
+
+		if ttl, err := db.TTL([]byte("plain")); err != nil || ttl == nil {
+			t.Errorf("expected the ttl to be kept, found (%v, %v)", ttl, err)
+		}
+	})
+}
+
+func TestTextSafe(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(raw goukv.Provider) {
+		keys, _ := NewKeyring("k1", bytes.Repeat([]byte("a"), 32))
+		binary := Wrap(raw, keys, Opts{})
+		text := Wrap(raw, keys, Opts{TextSafe: true})
+
+		binary.Put(&goukv.Entry{Key: []byte("a"), Value: []byte("v1")})
+		text.Put(&goukv.Entry{Key: []byte("b"), Value: []byte("v2")})
+
+		stored, _ := raw.Get([]byte("b"))
+		if !utf8.Valid(stored) || bytes.IndexByte(stored, 0) >= 0 {
+			t.Errorf("expected a text safe value, found (%q)", stored)
+		}
+
+		if id, err := KeyID(stored); err != nil || id != "k1" {
+			t.Errorf("expected (k1), found (%s, %v)", id, err)
+		}
+
+		// both forms are decrypted whatever the option
+		for _, db := range []*Provider{binary, text} {
+			for k, expected := range map[string]string{"a": "v1", "b": "v2"} {
+				if val, err := db.Get([]byte(k)); err != nil || string(val) != expected {
+					t.Errorf("expected (%s), found (%s, %v)", expected, string(val), err)
+				}
+			}
+		}
+
+		n, err := text.Reencrypt(context.Background(), ReencryptOpts{})
+		if err != nil || n != 1 {
+			t.Fatalf("expected (1) rewritten value, found (%d, %v)", n, err)
+		}
+
+		if stored, _ := raw.Get([]byte("a")); !bytes.HasPrefix(stored, textMagic) {
+			t.Errorf("expected the binary value to be converted, found (%q)", stored)
+		}
+	})
+}
+
+func TestPlaintext(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(raw goukv.Provider) {
+		raw.Put(&goukv.Entry{Key: []byte("k"), Value: []byte("v")})
+
+		keys, _ := NewKeyring("k1", bytes.Repeat([]byte("a"), 32))
+
+		if _, err := Wrap(raw, keys, Opts{}).Get([]byte("k")); err != ErrNotEncrypted {
+			t.Errorf("expected (%v), found (%v)", ErrNotEncrypted, err)
+		}
+
+		if _, err := NewKeyring("k1", []byte("short")); err == nil {
+			t.Error("expected an invalid key size to fail")
+		}
+	})
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(raw goukv.Provider) {
+		keys, _ := NewKeyring("k1", bytes.Repeat([]byte("a"), 32))
+		transformer, _ := WrapKeys(raw, KeyOpts{Mode: KeyDeterministic, Secret: bytes.Repeat([]byte("s"), 16)})
+		db := Wrap(transformer, keys, Opts{})
+
+		if !goukv.SupportsCompareAndSwap(db) {
+			t.Fatal("expected the wrappers to support compare-and-swap")
+		}
+
+		cases := []struct {
+			old, new []byte
+			swapped  bool
+		}{
+			{old: nil, new: []byte("v1"), swapped: true},
+			{old: nil, new: []byte("v2"), swapped: false},
+			{old: []byte("v2"), new: []byte("v3"), swapped: false},
+			{old: []byte("v1"), new: []byte("v2"), swapped: true},
+			{old: []byte("v2"), new: nil, swapped: true},
+			{old: []byte("v2"), new: []byte("v3"), swapped: false},
+		}
+
+		for i, c := range cases {
+			swapped, err := db.CompareAndSwap(&goukv.Entry{Key: []byte("k"), Value: c.new}, c.old)
+			if err != nil || swapped != c.swapped {
+				t.Errorf("case (%d): expected (%v), found (%v, %v)", i, c.swapped, swapped, err)
+			}
+		}
+
+		if val, err := db.Get([]byte("k")); val != nil {
+			t.Errorf("expected the key to be deleted, found (%s, %v)", string(val), err)
+		}
+	})
+}
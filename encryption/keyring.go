@@ -0,0 +1,102 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"sync"
+)
+
+// KeyProvider provides the encryption keys by id, the current key encrypts the new values
+// while the older ones are kept to decrypt the values they encrypted
+type KeyProvider interface {
+	// CurrentKey returns the id and the key used to encrypt the new values
+	CurrentKey() (string, []byte, error)
+
+	// Key returns the key of the specified id, ErrKeyIDNotFound should be returned if it doesn't exist
+	Key(id string) ([]byte, error)
+}
+
+// Keyring an in-memory KeyProvider
+type Keyring struct {
+	keys    map[string][]byte
+	current string
+	lock    sync.RWMutex
+}
+
+// NewKeyring initializes a new keyring using the specified current key
+func NewKeyring(id string, key []byte) (*Keyring, error) {
+	k := &Keyring{
+		keys: map[string][]byte{},
+	}
+
+	if err := k.Rotate(id, key); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// Add adds an old key, so the values it encrypted could be decrypted
+func (k *Keyring) Add(id string, key []byte) error {
+	if err := validate(id, key); err != nil {
+		return err
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if _, exists := k.keys[id]; exists {
+		return ErrKeyIDAlreadyExists
+	}
+
+	k.keys[id] = append([]byte{}, key...)
+
+	return nil
+}
+
+// Rotate adds the specified key and makes it the current one, the previous keys are kept
+func (k *Keyring) Rotate(id string, key []byte) error {
+	if err := k.Add(id, key); err != nil {
+		return err
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	k.current = id
+
+	return nil
+}
+
+// CurrentKey implements KeyProvider
+func (k *Keyring) CurrentKey() (string, []byte, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	return k.current, k.keys[k.current], nil
+}
+
+// Key implements KeyProvider
+func (k *Keyring) Key(id string) ([]byte, error) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	key, found := k.keys[id]
+	if !found {
+		return nil, ErrKeyIDNotFound
+	}
+
+	return key, nil
+}
+
+// validate validates the specified key id and key
+func validate(id string, key []byte) error {
+	if id == "" || len(id) > maxKeyIDLength {
+		return ErrInvalidKeyID
+	}
+
+	if _, err := aes.NewCipher(key); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,293 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/alash3al/goukv"
+)
+
+// the transform modes
+const (
+	// KeyHMAC replaces the keys by their HMAC-SHA256, it can't be reversed,
+	// so the scanners receive the transformed keys
+	KeyHMAC KeyMode = iota
+
+	// KeyDeterministic encrypts the keys using AES-GCM with a synthetic nonce derived from the key,
+	// the same key is always encrypted the same way and the scanners receive the original keys
+	KeyDeterministic
+)
+
+// error related variables
+var (
+	ErrInvalidSecret      = errors.New("the key transform secret must be at least 16 bytes")
+	ErrPrefixNotPreserved = errors.New("the scan prefix must end at the plaintext prefix boundary")
+	ErrInvalidKey         = errors.New("the transformed key is malformed")
+)
+
+// keyEncoding the encoding of the transformed part of the keys, it is url safe
+// and valid UTF-8, so the keys could be stored in text columns
+var keyEncoding = base64.RawURLEncoding
+
+// KeyMode the key transform mode
+type KeyMode int
+
+// KeyOpts the options of the key transform layer
+type KeyOpts struct {
+	// Mode the transform mode, KeyHMAC by default
+	Mode KeyMode
+
+	// Secret the transform secret, at least 16 bytes, it can't be rotated
+	// as the same key has to be transformed the same way to be found
+	Secret []byte
+
+	// Prefix returns the length of the plaintext prefix of the specified key, the prefixes are kept as is
+	// so the prefix scans over them still work, it is applied to the transformed keys too, so it must find
+	// the same boundary in both, i.e: SeparatorPrefix. the whole key is transformed if it is nil
+	Prefix func([]byte) int
+}
+
+// KeyTransformer a provider that transforms the keys before passing them to the provider it wraps
+type KeyTransformer struct {
+	provider goukv.Provider
+	opts     KeyOpts
+	mac      []byte
+	aead     cipher.AEAD
+}
+
+// WrapKeys wraps the specified provider using the specified key transform options
+func WrapKeys(p goukv.Provider, opts KeyOpts) (*KeyTransformer, error) {
+	if len(opts.Secret) < 16 {
+		return nil, ErrInvalidSecret
+	}
+
+	t := &KeyTransformer{
+		provider: p,
+		opts:     opts,
+		mac:      derive(opts.Secret, "goukv:keys:mac"),
+	}
+
+	if opts.Mode == KeyDeterministic {
+		block, err := aes.NewCipher(derive(opts.Secret, "goukv:keys:enc"))
+		if err != nil {
+			return nil, err
+		}
+
+		if t.aead, err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// SeparatorPrefix returns a prefix function that keeps the first n segments of the keys separated
+// by the specified separator (including it), i.e: `SeparatorPrefix(':', 1)` keeps `users:` of
+// `users:someone@example.com`. the separator must not be a base64url character.
+func SeparatorPrefix(sep byte, n int) func([]byte) int {
+	return func(k []byte) int {
+		end := 0
+
+		for i := 0; i < n; i++ {
+			idx := bytes.IndexByte(k[end:], sep)
+			if idx < 0 {
+				break
+			}
+
+			end += idx + 1
+		}
+
+		return end
+	}
+}
+
+// Unwrap implements goukv.Unwrapper
+func (t KeyTransformer) Unwrap() goukv.Provider {
+	return t.provider
+}
+
+// ScansOrdered implements goukv.OrderedScanner, the transformed keys don't preserve the plaintext order
+func (t KeyTransformer) ScansOrdered() bool {
+	return false
+}
+
+// WithContext implements goukv.ContextBinder
+func (t KeyTransformer) WithContext(ctx context.Context) goukv.Provider {
+	t.provider = goukv.WithContext(ctx, t.provider)
+
+	return &t
+}
+
+// Open implements goukv.Open
+func (t KeyTransformer) Open(dsn *goukv.DSN) (goukv.Provider, error) {
+	db, err := t.provider.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	t.provider = db
+
+	return &t, nil
+}
+
+// Put implements goukv.Put
+func (t KeyTransformer) Put(e *goukv.Entry) error {
+	return t.provider.Put(&goukv.Entry{Key: t.Transform(e.Key), Value: e.Value, TTL: e.TTL})
+}
+
+// Batch implements goukv.Batch
+func (t KeyTransformer) Batch(entries []*goukv.Entry) error {
+	transformed := make([]*goukv.Entry, 0, len(entries))
+
+	for _, entry := range entries {
+		transformed = append(transformed, &goukv.Entry{Key: t.Transform(entry.Key), Value: entry.Value, TTL: entry.TTL})
+	}
+
+	return t.provider.Batch(transformed)
+}
+
+// Get implements goukv.Get
+func (t KeyTransformer) Get(k []byte) ([]byte, error) {
+	return t.provider.Get(t.Transform(k))
+}
+
+// TTL implements goukv.TTL
+func (t KeyTransformer) TTL(k []byte) (*time.Time, error) {
+	return t.provider.TTL(t.Transform(k))
+}
+
+// Delete implements goukv.Delete
+func (t KeyTransformer) Delete(k []byte) error {
+	return t.provider.Delete(t.Transform(k))
+}
+
+// CompareAndSwap implements goukv.CompareAndSwapper
+func (t KeyTransformer) CompareAndSwap(e *goukv.Entry, old []byte) (bool, error) {
+	return goukv.CompareAndSwap(t.provider, &goukv.Entry{Key: t.Transform(e.Key), Value: e.Value, TTL: e.TTL}, old)
+}
+
+// Scan implements goukv.Scan, the prefix must be a plaintext prefix, the keys are ordered by
+// their transformed form, and the scanner receives the original keys unless the mode is KeyHMAC
+func (t KeyTransformer) Scan(opts goukv.ScanOpts) error {
+	if len(opts.Prefix) > 0 && t.prefixLen(opts.Prefix) != len(opts.Prefix) {
+		return ErrPrefixNotPreserved
+	}
+
+	if opts.Offset != nil {
+		opts.Offset = t.Transform(opts.Offset)
+	}
+
+	if opts.Scanner == nil || t.aead == nil {
+		return t.provider.Scan(opts)
+	}
+
+	var reverseErr error
+	scanner := opts.Scanner
+
+	opts.Scanner = func(k, v []byte) bool {
+		original, err := t.Reverse(k)
+		if err != nil {
+			reverseErr = err
+			return false
+		}
+
+		return scanner(original, v)
+	}
+
+	if err := t.provider.Scan(opts); err != nil {
+		return err
+	}
+
+	return reverseErr
+}
+
+// Close implements goukv.Close
+func (t KeyTransformer) Close() error {
+	return t.provider.Close()
+}
+
+// Transform returns the stored form of the specified key
+func (t KeyTransformer) Transform(k []byte) []byte {
+	n := t.prefixLen(k)
+	suffix := k[n:]
+
+	// the whole key is hashed, so the same suffix under different prefixes can't be linked
+	// and a nonce is never reused for different additional data
+	var transformed []byte
+	if t.aead != nil {
+		nonce := t.sum(k)[:t.aead.NonceSize()]
+		transformed = t.aead.Seal(nonce, nonce, suffix, k[:n])
+	} else {
+		transformed = t.sum(k)
+	}
+
+	out := make([]byte, n, n+keyEncoding.EncodedLen(len(transformed)))
+	copy(out, k[:n])
+
+	return append(out, keyEncoding.EncodeToString(transformed)...)
+}
+
+// Reverse returns the original form of the specified stored key, it fails if the mode is KeyHMAC
+func (t KeyTransformer) Reverse(k []byte) ([]byte, error) {
+	if t.aead == nil {
+		return nil, ErrInvalidKey
+	}
+
+	n := t.prefixLen(k)
+
+	sealed, err := keyEncoding.DecodeString(string(k[n:]))
+	if err != nil || len(sealed) < t.aead.NonceSize() {
+		return nil, ErrInvalidKey
+	}
+
+	nonce := sealed[:t.aead.NonceSize()]
+
+	suffix, err := t.aead.Open(nil, nonce, sealed[t.aead.NonceSize():], k[:n])
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	original := append(append([]byte{}, k[:n]...), suffix...)
+	if !hmac.Equal(nonce, t.sum(original)[:len(nonce)]) {
+		return nil, ErrInvalidKey
+	}
+
+	return original, nil
+}
+
+// prefixLen returns the length of the plaintext prefix of the specified key
+func (t KeyTransformer) prefixLen(k []byte) int {
+	if t.opts.Prefix == nil {
+		return 0
+	}
+
+	n := t.opts.Prefix(k)
+	if n < 0 || n > len(k) {
+		return 0
+	}
+
+	return n
+}
+
+// sum returns the HMAC-SHA256 of the specified data
+func (t KeyTransformer) sum(data []byte) []byte {
+	h := hmac.New(sha256.New, t.mac)
+	h.Write(data)
+
+	return h.Sum(nil)
+}
+
+// derive derives a sub key of the specified secret for the specified purpose
+func derive(secret []byte, purpose string) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(purpose))
+
+	return h.Sum(nil)
+}
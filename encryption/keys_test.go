@@ -0,0 +1,85 @@
+package encryption
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/internal/testutil"
+)
+
+func TestKeyTransform(t *testing.T) {
+	for _, mode := range []KeyMode{KeyHMAC, KeyDeterministic} {
+		testutil.OpenDBAndDo(t, func(raw goukv.Provider) {
+			db, err := WrapKeys(raw, KeyOpts{
+				Mode:   mode,
+				Secret: bytes.Repeat([]byte("s"), 32),
+				Prefix: SeparatorPrefix(':', 1),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			db.Batch([]*goukv.Entry{
+				{Key: []byte("users:someone@example.com"), Value: []byte("1")},
+				{Key: []byte("users:other@example.com"), Value: []byte("2")},
+				{Key: []byte("admins:someone@example.com"), Value: []byte("3")},
+			})
+
+			if val, err := db.Get([]byte("users:someone@example.com")); err != nil || string(val) != "1" {
+				t.Errorf("expected (1), found (%s, %v)", string(val), err)
+			}
+
+			raw.Scan(goukv.ScanOpts{Scanner: func(k, v []byte) bool {
+				if bytes.Contains(k, []byte("@")) {
+					t.Errorf("expected (%s) to be transformed", string(k))
+				}
+				return true
+			}})
+
+			found := []string{}
+			err = db.Scan(goukv.ScanOpts{Prefix: []byte("users:"), Scanner: func(k, v []byte) bool {
+				found = append(found, string(k))
+				return true
+			}})
+			if err != nil || len(found) != 2 {
+				t.Fatalf("expected (2) users, found (%v, %v)", found, err)
+			}
+
+			if mode == KeyDeterministic && found[0] != "users:someone@example.com" && found[1] != "users:someone@example.com" {
+				t.Errorf("expected the original keys, found %v", found)
+			}
+
+			if err := db.Scan(goukv.ScanOpts{Prefix: []byte("users:some")}); err != ErrPrefixNotPreserved {
+				t.Errorf("expected (%v), found (%v)", ErrPrefixNotPreserved, err)
+			}
+		})
+	}
+}
+
+func TestKeyTransformReverse(t *testing.T) {
+	db, _ := WrapKeys(nil, KeyOpts{Mode: KeyDeterministic, Secret: bytes.Repeat([]byte("s"), 16)})
+
+	transformed := db.Transform([]byte("someone@example.com"))
+	if !bytes.Equal(transformed, db.Transform([]byte("someone@example.com"))) {
+		t.Error("expected the transform to be deterministic")
+	}
+
+	if original, err := db.Reverse(transformed); err != nil || string(original) != "someone@example.com" {
+		t.Errorf("expected (someone@example.com), found (%s, %v)", string(original), err)
+	}
+
+	if transformed[10] == 'A' {
+		transformed[10] = 'B'
+	} else {
+		transformed[10] = 'A'
+	}
+
+	if _, err := db.Reverse(transformed); err != ErrInvalidKey {
+		t.Errorf("expected (%v), found (%v)", ErrInvalidKey, err)
+	}
+
+	if _, err := WrapKeys(nil, KeyOpts{Secret: []byte("short")}); err != ErrInvalidSecret {
+		t.Errorf("expected (%v), found (%v)", ErrInvalidSecret, err)
+	}
+}
@@ -0,0 +1,133 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/alash3al/goukv"
+)
+
+const (
+	defaultReencryptBatchSize = 100
+)
+
+// ReencryptOpts the options of the re-encryption job
+type ReencryptOpts struct {
+	// Prefix limits the job to the keys having the specified prefix
+	Prefix []byte
+
+	// BatchSize the number of values read then rewritten at once, 100 if it isn't set
+	BatchSize int
+}
+
+// Reencrypt rewrites the values that aren't encrypted by the current key (or not encrypted at all
+// if AllowPlaintext is set) or whose form doesn't match the TextSafe option, using the current key
+// and the configured form while keeping their TTL, it returns the number of rewritten values.
+// the store is scanned page by page and each page is rewritten using a single batch,
+// a value written by someone else between reading and rewriting its page would be overwritten,
+// so it is better to run it while the writes are paused or at least rare.
+func (p Provider) Reencrypt(ctx context.Context, opts ReencryptOpts) (int, error) {
+	if opts.BatchSize < 1 {
+		opts.BatchSize = defaultReencryptBatchSize
+	}
+
+	currentID, _, err := p.keys.CurrentKey()
+	if err != nil {
+		return 0, err
+	}
+
+	rewritten := 0
+	var offset []byte
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return rewritten, err
+		}
+
+		keys, vals := [][]byte{}, [][]byte{}
+
+		// the offset is included then skipped, as not all the providers exclude it the same way
+		err := p.provider.Scan(goukv.ScanOpts{
+			Prefix:        opts.Prefix,
+			Offset:        offset,
+			IncludeOffset: true,
+			Scanner: func(k, v []byte) bool {
+				if offset != nil && bytes.Equal(k, offset) {
+					return true
+				}
+
+				keys = append(keys, append([]byte{}, k...))
+				vals = append(vals, append([]byte{}, v...))
+
+				return len(keys) < opts.BatchSize
+			},
+		})
+		if err != nil {
+			return rewritten, err
+		}
+
+		entries := []*goukv.Entry{}
+
+		for i, k := range keys {
+			entry, err := p.reencrypt(currentID, k, vals[i])
+			if err != nil {
+				return rewritten, err
+			}
+
+			if entry != nil {
+				entries = append(entries, entry)
+			}
+		}
+
+		if len(entries) > 0 {
+			if err := p.provider.Batch(entries); err != nil {
+				return rewritten, err
+			}
+
+			rewritten += len(entries)
+		}
+
+		if len(keys) < opts.BatchSize {
+			return rewritten, nil
+		}
+
+		offset = keys[len(keys)-1]
+	}
+}
+
+// reencrypt returns the entry that rewrites the specified value using the current key,
+// nil is returned if it is already encrypted by the current key in the configured form or it is expired/deleted
+func (p Provider) reencrypt(currentID string, k, val []byte) (*goukv.Entry, error) {
+	if id, _, text, err := parse(val); err == nil && id == currentID && text == p.opts.TextSafe {
+		return nil, nil
+	}
+
+	plain, err := p.Decrypt(k, val)
+	if err != nil {
+		return nil, err
+	}
+
+	expires, err := p.provider.TTL(k)
+	if err == goukv.ErrKeyNotFound {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var ttl time.Duration
+	if expires != nil {
+		if ttl = time.Until(*expires); ttl <= 0 {
+			return nil, nil
+		}
+	}
+
+	encrypted, err := p.Encrypt(k, plain)
+	if err != nil {
+		return nil, err
+	}
+
+	return &goukv.Entry{Key: k, Value: encrypted, TTL: ttl}, nil
+}
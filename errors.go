@@ -8,4 +8,16 @@ var (
 	ErrDriverNotFound      = errors.New("the requested driver isn't found")
 	ErrKeyExpired          = errors.New("the specified key is expired")
 	ErrKeyNotFound         = errors.New("the specified key couldn't be found")
+
+	ErrMiddlewareAlreadyExists = errors.New("the specified middleware name already exists")
+	ErrMiddlewareNotFound      = errors.New("the requested middleware isn't found")
+
+	ErrLoggerAlreadyExists = errors.New("the specified logger name already exists")
+	ErrLoggerNotFound      = errors.New("the requested logger isn't found")
+	ErrInvalidLogKeys      = errors.New("the log_keys option must be one of hash, truncate or plain")
+
+	ErrInvalidNamespace = errors.New("the namespace name must be non-empty and must not contain \\x1f")
+
+	ErrCompareAndSwapUnsupported = errors.New("the provider doesn't support conditional writes, see goukv.CompareAndSwapper")
+	ErrWatchUnsupported          = errors.New("the provider doesn't support watching the changes, see goukv.Watcher")
 )
@@ -0,0 +1,19 @@
+// Package keylock holds the striped locks serializing the read-modify-write updates of the same key,
+// the locks are local to the process, they give no protection against the other processes sharing the same store.
+package keylock
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// locks the stripes, the keys are spread over them by their hash
+var locks [64]sync.Mutex
+
+// Of returns the lock of the specified key
+func Of(key []byte) *sync.Mutex {
+	h := fnv.New32a()
+	h.Write(key)
+
+	return &locks[h.Sum32()%uint32(len(locks))]
+}
@@ -0,0 +1,22 @@
+// Package scanutil holds the scan helpers shared by the instrumentation packages.
+package scanutil
+
+import (
+	"github.com/alash3al/goukv"
+)
+
+// Count decorates the scanner of the specified options to count the items it receives,
+// the returned counter is updated while the scan is running
+func Count(opts *goukv.ScanOpts) *int {
+	items := new(int)
+	scanner := opts.Scanner
+
+	if scanner != nil {
+		opts.Scanner = func(k, v []byte) bool {
+			*items++
+			return scanner(k, v)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,74 @@
+// Package testutil holds the helpers shared by the tests of the goukv packages.
+package testutil
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/providers/leveldb"
+)
+
+// OpenDBAndDo opens a leveldb provider in a temporary directory of the specified test,
+// passes it to the specified function then closes it, the directory is removed once the test is done
+func OpenDBAndDo(t testing.TB, fn func(goukv.Provider)) {
+	t.Helper()
+
+	dsn, err := goukv.NewDSN("leveldb://" + t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := leveldb.Provider{}.Open(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	fn(db)
+}
+
+// Eventually calls the specified condition until it holds, the test fails if it doesn't hold
+// within the specified timeout, it is used to wait for the entries to expire without assuming
+// how long it takes
+func Eventually(t testing.TB, timeout time.Duration, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("the condition didn't hold within (%s)", timeout)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// Clock a fake clock that only moves when it is advanced
+type Clock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+// NewClock returns a fake clock set to the current time
+func NewClock() *Clock {
+	return &Clock{t: time.Now()}
+}
+
+// Now returns the time of the clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.t
+}
+
+// Advance moves the clock forward by the specified duration
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.t = c.t.Add(d)
+}
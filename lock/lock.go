@@ -0,0 +1,260 @@
+// Package lock implements distributed locks on top of goukv providers that support conditional writes.
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alash3al/goukv"
+)
+
+const (
+	keyPrefix = "lock:"
+
+	defaultRetryInterval = 100 * time.Millisecond
+)
+
+// error related variables
+var (
+	ErrCompareAndSwapUnsupported = goukv.ErrCompareAndSwapUnsupported
+	ErrLocked                    = errors.New("the lock is held by another owner")
+	ErrLockLost                  = errors.New("the lease has expired or the lock has been released")
+	ErrInvalidTTL                = errors.New("the ttl must be positive")
+)
+
+// Opts the options of a locker
+type Opts struct {
+	// RetryInterval how often Acquire retries while the lock is held by another owner, 100ms by default
+	RetryInterval time.Duration
+
+	// AutoRenew refreshes the leases in the background every third of their ttl until they are released,
+	// see Lease.Lost
+	AutoRenew bool
+}
+
+// Locker acquires the locks stored using the following keys:
+//   - `lock:<url escaped key>` holding the random id of the current owner, it expires (Entry.TTL) after the ttl
+//   - `lock:<url escaped key>:fence` holding the last fencing token of the lock
+type Locker struct {
+	provider goukv.Provider
+	opts     Opts
+}
+
+// New initializes a new locker on top of the specified provider,
+// ErrCompareAndSwapUnsupported is returned if it doesn't support them (see goukv.SupportsCompareAndSwap)
+func New(p goukv.Provider, opts Opts) (*Locker, error) {
+	if !goukv.SupportsCompareAndSwap(p) {
+		return nil, ErrCompareAndSwapUnsupported
+	}
+
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = defaultRetryInterval
+	}
+
+	return &Locker{
+		provider: p,
+		opts:     opts,
+	}, nil
+}
+
+// Acquire blocks until the lock of the specified key is acquired for the specified ttl
+// or the specified context is done
+func (l *Locker) Acquire(ctx context.Context, key []byte, ttl time.Duration) (*Lease, error) {
+	ticker := time.NewTicker(l.opts.RetryInterval)
+	defer ticker.Stop()
+
+	for {
+		lease, err := l.TryAcquire(key, ttl)
+		if err != ErrLocked {
+			return lease, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// TryAcquire acquires the lock of the specified key for the specified ttl,
+// ErrLocked is returned if it is held by another owner
+func (l *Locker) TryAcquire(key []byte, ttl time.Duration) (*Lease, error) {
+	if ttl <= 0 {
+		return nil, ErrInvalidTTL
+	}
+
+	owner, err := newOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	lease := &Lease{
+		locker: l,
+		key:    []byte(keyPrefix + url.QueryEscape(string(key))),
+		owner:  owner,
+		ttl:    ttl,
+		stop:   make(chan struct{}),
+		lost:   make(chan struct{}),
+	}
+
+	acquired, err := goukv.CompareAndSwap(l.provider, &goukv.Entry{Key: lease.key, Value: owner, TTL: ttl}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if !acquired {
+		return nil, ErrLocked
+	}
+
+	// the token is issued while holding the lock, so the tokens of the successive owners are increasing
+	if lease.Token, err = l.nextToken(lease.key); err != nil {
+		lease.Release()
+		return nil, err
+	}
+
+	if err := lease.Refresh(); err != nil {
+		lease.Release()
+		return nil, err
+	}
+
+	if l.opts.AutoRenew {
+		go lease.renew()
+	}
+
+	return lease, nil
+}
+
+// nextToken increments the fencing token of the specified lock key and returns it
+func (l *Locker) nextToken(key []byte) (uint64, error) {
+	fence := append(append([]byte{}, key...), ":fence"...)
+
+	for {
+		old, err := l.provider.Get(fence)
+		if err != nil && err != goukv.ErrKeyNotFound {
+			return 0, err
+		}
+
+		var current uint64
+		if old != nil {
+			if current, err = strconv.ParseUint(string(old), 10, 64); err != nil {
+				return 0, err
+			}
+		}
+
+		next := current + 1
+
+		swapped, err := goukv.CompareAndSwap(l.provider, &goukv.Entry{Key: fence, Value: []byte(strconv.FormatUint(next, 10))}, old)
+		if err != nil {
+			return 0, err
+		}
+
+		if swapped {
+			return next, nil
+		}
+	}
+}
+
+// Lease an acquired lock
+type Lease struct {
+	// Token the fencing token of the lease, it is greater than the tokens of the previous owners of the lock,
+	// so the resources guarded by the lock could reject the writes of a stale owner
+	Token uint64
+
+	locker   *Locker
+	key      []byte
+	owner    []byte
+	ttl      time.Duration
+	stop     chan struct{}
+	stopOnce sync.Once
+	lost     chan struct{}
+	lostOnce sync.Once
+}
+
+// Refresh extends the lease by its ttl, ErrLockLost is returned if it has expired or has been released
+func (l *Lease) Refresh() error {
+	refreshed, err := goukv.CompareAndSwap(l.locker.provider, &goukv.Entry{Key: l.key, Value: l.owner, TTL: l.ttl}, l.owner)
+	if err != nil {
+		return err
+	}
+
+	if !refreshed {
+		l.markLost()
+		return ErrLockLost
+	}
+
+	return nil
+}
+
+// Release releases the lock and stops its automatic renewal,
+// ErrLockLost is returned if the lease has expired or has been released
+func (l *Lease) Release() error {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+
+	released, err := goukv.CompareAndSwap(l.locker.provider, &goukv.Entry{Key: l.key}, l.owner)
+	if err != nil {
+		return err
+	}
+
+	l.markLost()
+
+	if !released {
+		return ErrLockLost
+	}
+
+	return nil
+}
+
+// Lost returns a channel that is closed once the lease is known to be lost or released,
+// i.e: when an automatic renewal fails because the lease has expired
+func (l *Lease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// renew refreshes the lease every third of its ttl until it is released or lost,
+// the transient errors are retried on the next tick
+func (l *Lease) renew() {
+	interval := l.ttl / 3
+	if interval <= 0 {
+		interval = l.ttl
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			if err := l.Refresh(); err == ErrLockLost {
+				return
+			}
+		}
+	}
+}
+
+// markLost closes the lost channel once
+func (l *Lease) markLost() {
+	l.lostOnce.Do(func() {
+		close(l.lost)
+	})
+}
+
+// newOwner returns a random owner id
+func newOwner() ([]byte, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+
+	return []byte(hex.EncodeToString(buf)), nil
+}
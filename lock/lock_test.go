@@ -0,0 +1,143 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/internal/testutil"
+)
+
+func TestLock(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		locker, err := New(db, Opts{RetryInterval: 10 * time.Millisecond})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		first, err := locker.TryAcquire([]byte("cron"), time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := locker.TryAcquire([]byte("cron"), time.Minute); err != ErrLocked {
+			t.Errorf("expected (%v), found (%v)", ErrLocked, err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+
+		if _, err := locker.Acquire(ctx, []byte("cron"), time.Minute); err != context.DeadlineExceeded {
+			t.Errorf("expected (%v), found (%v)", context.DeadlineExceeded, err)
+		}
+
+		if err := first.Refresh(); err != nil {
+			t.Error(err)
+		}
+
+		go first.Release()
+
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		second, err := locker.Acquire(ctx, []byte("cron"), time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if second.Token <= first.Token {
+			t.Errorf("expected the token (%d) to be greater than (%d)", second.Token, first.Token)
+		}
+
+		if err := first.Release(); err != ErrLockLost {
+			t.Errorf("expected (%v), found (%v)", ErrLockLost, err)
+		}
+
+		if err := second.Release(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestExpiration(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		locker, _ := New(db, Opts{})
+		renewer, _ := New(db, Opts{AutoRenew: true})
+
+		expiring, _ := locker.TryAcquire([]byte("expiring"), 30*time.Millisecond)
+		renewed, _ := renewer.TryAcquire([]byte("renewed"), 300*time.Millisecond)
+		renewedExpiry := time.Now().Add(300 * time.Millisecond)
+
+		// the expired lease could be acquired by another owner
+		testutil.Eventually(t, 5*time.Second, func() bool {
+			_, err := renewer.TryAcquire([]byte("expiring"), time.Minute)
+			return err == nil
+		})
+
+		// the renewed lease outlives its first ttl
+		testutil.Eventually(t, 5*time.Second, func() bool {
+			return time.Now().After(renewedExpiry)
+		})
+
+		if err := expiring.Refresh(); err != ErrLockLost {
+			t.Errorf("expected (%v), found (%v)", ErrLockLost, err)
+		}
+
+		if _, err := locker.TryAcquire([]byte("renewed"), time.Minute); err != ErrLocked {
+			t.Errorf("expected (%v), found (%v)", ErrLocked, err)
+		}
+
+		select {
+		case <-renewed.Lost():
+			t.Error("expected the renewed lease to be held")
+		default:
+		}
+
+		renewed.Release()
+
+		select {
+		case <-renewed.Lost():
+		default:
+			t.Error("expected the released lease to be lost")
+		}
+	})
+}
+
+// unsupported a provider that doesn't implement goukv.CompareAndSwapper
+type unsupported struct {
+	goukv.Provider
+}
+
+func TestUnsupportedProvider(t *testing.T) {
+	if _, err := New(unsupported{}, Opts{}); err != ErrCompareAndSwapUnsupported {
+		t.Errorf("expected (%v), found (%v)", ErrCompareAndSwapUnsupported, err)
+	}
+
+	// the wrappers implement goukv.CompareAndSwapper but the wrapped provider doesn't
+	if _, err := New(goukv.Namespace(unsupported{}, "ns"), Opts{}); err != ErrCompareAndSwapUnsupported {
+		t.Errorf("expected (%v), found (%v)", ErrCompareAndSwapUnsupported, err)
+	}
+}
+
+func TestWrappedProvider(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		locker, err := New(goukv.Namespace(goukv.NewCached(db, goukv.CacheOpts{}), "ns"), Opts{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lease, err := locker.TryAcquire([]byte("k"), time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := locker.TryAcquire([]byte("k"), time.Minute); err != ErrLocked {
+			t.Errorf("expected (%v), found (%v)", ErrLocked, err)
+		}
+
+		if err := lease.Release(); err != nil {
+			t.Error(err)
+		}
+	})
+}
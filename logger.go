@@ -0,0 +1,247 @@
+package goukv
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// loggers a registry for the loggers that could be selected using the dsn
+var (
+	loggersMap           = map[string]Logger{}
+	loggersLock          = &sync.RWMutex{}
+	defaultLogger Logger = nopLogger{}
+)
+
+// Logger an interface describes a structured logger, args are key-value pairs as in `log/slog`,
+// so a *slog.Logger could be used as is
+type Logger interface {
+	Log(ctx context.Context, level slog.Level, msg string, args ...interface{})
+}
+
+// KeyFormatter formats a key before logging it, i.e: to hash or truncate it
+type KeyFormatter func([]byte) string
+
+// LogOpts the options of the operations logger middleware
+type LogOpts struct {
+	// Level the level of the operations records, failed operations are logged using slog.LevelError
+	// unless the error is goukv.ErrKeyNotFound or goukv.ErrKeyExpired
+	Level slog.Level
+
+	// Keys formats the keys and the scan prefixes, HashKeys is used if it is nil
+	Keys KeyFormatter
+}
+
+// nopLogger a logger that discards everything
+type nopLogger struct{}
+
+// Log implements goukv.Logger
+func (nopLogger) Log(context.Context, slog.Level, string, ...interface{}) {}
+
+// SetLogger sets the default logger, it is used by the providers unless another one is
+// selected using the `logger` dsn option, nil discards the records (the default)
+func SetLogger(l Logger) {
+	loggersLock.Lock()
+	defer loggersLock.Unlock()
+
+	if l == nil {
+		l = nopLogger{}
+	}
+
+	defaultLogger = l
+}
+
+// RegisterLogger register a new logger, so it could be selected using the `logger` dsn option
+func RegisterLogger(name string, l Logger) error {
+	loggersLock.Lock()
+	defer loggersLock.Unlock()
+
+	if loggersMap[name] != nil {
+		return ErrLoggerAlreadyExists
+	}
+
+	loggersMap[name] = l
+
+	return nil
+}
+
+// GetLogger returns a logger from the registery, an empty name means the default logger
+func GetLogger(name string) (Logger, error) {
+	loggersLock.RLock()
+	defer loggersLock.RUnlock()
+
+	if name == "" {
+		return defaultLogger, nil
+	}
+
+	if loggersMap[name] == nil {
+		return nil, ErrLoggerNotFound
+	}
+
+	return loggersMap[name], nil
+}
+
+// LoggerFromDSN returns the logger selected by the `logger` dsn option or the default one
+func LoggerFromDSN(dsn *DSN) (Logger, error) {
+	return GetLogger(dsn.GetString("logger"))
+}
+
+// HashKeys a key formatter that replaces the keys by the first 8 bytes of their sha256 hash
+func HashKeys(k []byte) string {
+	sum := sha256.Sum256(k)
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// PlainKeys a key formatter that logs the keys as is
+func PlainKeys(k []byte) string {
+	return string(k)
+}
+
+// TruncateKeys returns a key formatter that keeps the first n bytes of the keys followed by their length
+func TruncateKeys(n int) KeyFormatter {
+	return func(k []byte) string {
+		if len(k) <= n {
+			return string(k)
+		}
+
+		return string(k[:n]) + "...(" + strconv.Itoa(len(k)) + ")"
+	}
+}
+
+// LoggerMiddleware returns a middleware that logs the operations of the provider using the specified logger
+func LoggerMiddleware(l Logger, opts LogOpts) Middleware {
+	if opts.Keys == nil {
+		opts.Keys = HashKeys
+	}
+
+	log := func(op string, start time.Time, err error, args ...interface{}) {
+		level := opts.Level
+		args = append(args, "duration", time.Since(start))
+
+		if err != nil {
+			args = append(args, "error", err.Error())
+
+			if err != ErrKeyNotFound && err != ErrKeyExpired {
+				level = slog.LevelError
+			}
+		}
+
+		l.Log(context.Background(), level, "goukv."+op, args...)
+	}
+
+	return Middleware{
+		Put: func(next PutFunc) PutFunc {
+			return func(e *Entry) error {
+				start := time.Now()
+				err := next(e)
+				log("put", start, err, "key", opts.Keys(e.Key), "size", len(e.Value), "ttl", e.TTL)
+
+				return err
+			}
+		},
+
+		Get: func(next GetFunc) GetFunc {
+			return func(k []byte) ([]byte, error) {
+				start := time.Now()
+				val, err := next(k)
+				log("get", start, err, "key", opts.Keys(k))
+
+				return val, err
+			}
+		},
+
+		TTL: func(next TTLFunc) TTLFunc {
+			return func(k []byte) (*time.Time, error) {
+				start := time.Now()
+				t, err := next(k)
+				log("ttl", start, err, "key", opts.Keys(k))
+
+				return t, err
+			}
+		},
+
+		Delete: func(next DeleteFunc) DeleteFunc {
+			return func(k []byte) error {
+				start := time.Now()
+				err := next(k)
+				log("delete", start, err, "key", opts.Keys(k))
+
+				return err
+			}
+		},
+
+		Batch: func(next BatchFunc) BatchFunc {
+			return func(entries []*Entry) error {
+				start := time.Now()
+				err := next(entries)
+				log("batch", start, err, "size", len(entries))
+
+				return err
+			}
+		},
+
+		Scan: func(next ScanFunc) ScanFunc {
+			return func(scanOpts ScanOpts) error {
+				items := 0
+				scanner := scanOpts.Scanner
+
+				if scanner != nil {
+					scanOpts.Scanner = func(k, v []byte) bool {
+						items++
+						return scanner(k, v)
+					}
+				}
+
+				start := time.Now()
+				err := next(scanOpts)
+				log("scan", start, err, "prefix", opts.Keys(scanOpts.Prefix), "reverse", scanOpts.ReverseScan, "items", items)
+
+				return err
+			}
+		},
+	}
+}
+
+// loggerMiddlewareFromDSN builds the `logger` middleware using the `log_level`, `log_keys`
+// (hash, truncate or plain) and `log_keys_length` dsn options
+func loggerMiddlewareFromDSN(dsn *DSN) (Middleware, error) {
+	l, err := LoggerFromDSN(dsn)
+	if err != nil {
+		return Middleware{}, err
+	}
+
+	opts := LogOpts{Level: slog.LevelDebug}
+
+	if level := dsn.GetString("log_level"); level != "" {
+		if err := opts.Level.UnmarshalText([]byte(level)); err != nil {
+			return Middleware{}, err
+		}
+	}
+
+	switch strings.ToLower(dsn.GetString("log_keys")) {
+	case "", "hash":
+		opts.Keys = HashKeys
+	case "plain":
+		opts.Keys = PlainKeys
+	case "truncate":
+		n := dsn.GetInt("log_keys_length")
+		if n < 1 {
+			n = 8
+		}
+		opts.Keys = TruncateKeys(n)
+	default:
+		return Middleware{}, ErrInvalidLogKeys
+	}
+
+	return LoggerMiddleware(l, opts), nil
+}
+
+func init() {
+	RegisterMiddleware("logger", loggerMiddlewareFromDSN)
+}
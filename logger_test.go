@@ -0,0 +1,111 @@
+package goukv
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// record a log record captured by the testLogger
+type record struct {
+	level slog.Level
+	msg   string
+	args  []interface{}
+}
+
+// testLogger a logger that captures the records
+type testLogger struct {
+	records []record
+}
+
+func (l *testLogger) Log(_ context.Context, level slog.Level, msg string, args ...interface{}) {
+	l.records = append(l.records, record{level: level, msg: msg, args: args})
+}
+
+func (r record) get(key string) interface{} {
+	for i := 0; i+1 < len(r.args); i += 2 {
+		if r.args[i] == key {
+			return r.args[i+1]
+		}
+	}
+
+	return nil
+}
+
+func TestLoggerMiddleware(t *testing.T) {
+	l := &testLogger{}
+	db := Wrap(newMemoryProvider(), LoggerMiddleware(l, LogOpts{Level: slog.LevelInfo}))
+
+	db.Put(&Entry{Key: []byte("secret-key"), Value: []byte("v")})
+	db.TTL([]byte("not-found"))
+
+	if len(l.records) != 2 {
+		t.Fatalf("expected (2) records, found (%d)", len(l.records))
+	}
+
+	put := l.records[0]
+	if put.msg != "goukv.put" || put.level != slog.LevelInfo {
+		t.Errorf("unexpected put record (%s, %s)", put.msg, put.level)
+	}
+
+	if put.get("key") != HashKeys([]byte("secret-key")) {
+		t.Errorf("expected the key to be hashed, found (%v)", put.get("key"))
+	}
+
+	// not found isn't a failure
+	if ttl := l.records[1]; ttl.level != slog.LevelInfo || ttl.get("error") != ErrKeyNotFound.Error() {
+		t.Errorf("unexpected ttl record (%s, %v)", ttl.level, ttl.get("error"))
+	}
+}
+
+func TestTruncateKeys(t *testing.T) {
+	truncate := TruncateKeys(4)
+
+	if found := truncate([]byte("key")); found != "key" {
+		t.Errorf("expected (key), found (%s)", found)
+	}
+
+	if found := truncate([]byte("users/1234")); found != "user...(10)" {
+		t.Errorf("expected (user...(10)), found (%s)", found)
+	}
+}
+
+func TestLoggerFromDSN(t *testing.T) {
+	l := &testLogger{}
+	registerLogger(t, "test", l)
+	registerProvider(t, "memory-logger", newMemoryProvider())
+
+	db, err := Open("memory-logger", "memory://?middlewares=logger&logger=test&log_keys=truncate&log_keys_length=2&log_level=warn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.Get([]byte("key"))
+
+	if len(l.records) != 1 || l.records[0].level != slog.LevelWarn || l.records[0].get("key") != "ke...(3)" {
+		t.Errorf("unexpected records %v", l.records)
+	}
+
+	for _, dsn := range []string{"memory://?middlewares=logger&logger=unknown", "memory://?middlewares=logger&log_keys=base64"} {
+		if _, err := Open("memory-logger", dsn); err == nil || !strings.Contains(err.Error(), "log") {
+			t.Errorf("expected (%s) to fail, found (%v)", dsn, err)
+		}
+	}
+}
+
+// registerLogger registers the specified logger and removes it from the registry once the test is done
+func registerLogger(t *testing.T, name string, l Logger) {
+	t.Helper()
+
+	if err := RegisterLogger(name, l); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		loggersLock.Lock()
+		defer loggersLock.Unlock()
+
+		delete(loggersMap, name)
+	})
+}
@@ -0,0 +1,264 @@
+// Package mapper maps structs to goukv keys and maintains their secondary indexes.
+package mapper
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/alash3al/goukv"
+)
+
+// the struct tag values, i.e: `goukv:"pk"`
+const (
+	tagName    = "goukv"
+	tagPrimary = "pk"
+	tagIndex   = "index"
+)
+
+// error related variables
+var (
+	ErrNotStruct       = errors.New("the mapped type must be a struct")
+	ErrNoPrimaryKey    = errors.New("the mapped type must have exactly one `goukv:\"pk\"` field")
+	ErrEmptyPrimaryKey = errors.New("the primary key must not be empty")
+	ErrUnknownIndex    = errors.New("the requested field isn't indexed")
+	ErrUnexportedField = errors.New("the primary key and the indexed fields must be exported")
+)
+
+// Repository maps the T structs to keys of a provider, the struct must have one primary key field
+// tagged by `goukv:"pk"`, and the fields tagged by `goukv:"index"` are indexed, so they could be
+// looked up using FindBy. the keys layout is:
+//   - records: `<name>:r:<pk>`
+//   - indexes: `<name>:i:<field>:<value>:<pk>`
+//
+// where the name, pk and values are url escaped, so the keys are valid UTF-8.
+type Repository[T any] struct {
+	provider goukv.Provider
+	name     string
+	codec    goukv.Codec
+	primary  int
+	indexes  map[string]int
+}
+
+// New initializes a new repository named by the specified name (the keys prefix) on top of
+// the specified provider, the records are encoded using the specified codec
+func New[T any](p goukv.Provider, name string, codec goukv.Codec) (*Repository[T], error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return nil, ErrNotStruct
+	}
+
+	r := &Repository[T]{
+		provider: p,
+		name:     url.QueryEscape(name),
+		codec:    codec,
+		primary:  -1,
+		indexes:  map[string]int{},
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		for _, tag := range strings.Split(typ.Field(i).Tag.Get(tagName), ",") {
+			tag = strings.TrimSpace(tag)
+			if (tag == tagPrimary || tag == tagIndex) && !typ.Field(i).IsExported() {
+				return nil, ErrUnexportedField
+			}
+
+			switch tag {
+			case tagPrimary:
+				if r.primary != -1 {
+					return nil, ErrNoPrimaryKey
+				}
+				r.primary = i
+			case tagIndex:
+				r.indexes[typ.Field(i).Name] = i
+			}
+		}
+	}
+
+	if r.primary == -1 {
+		return nil, ErrNoPrimaryKey
+	}
+
+	return r, nil
+}
+
+// Save stores the specified record and updates its index entries using a single batch,
+// so whether they are written atomically depends on the guarantees of the provider's Batch
+func (r *Repository[T]) Save(v *T) error {
+	pk := format(reflect.ValueOf(v).Elem().Field(r.primary))
+	if pk == "" {
+		return ErrEmptyPrimaryKey
+	}
+
+	data, err := r.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	entries := []*goukv.Entry{{Key: r.recordKey(pk), Value: data}}
+	current := r.indexKeys(pk, v)
+
+	old, err := r.Get(pk)
+	if err != nil && err != goukv.ErrKeyNotFound {
+		return err
+	}
+
+	// the stale index entries of the previous version of the record
+	if old != nil {
+		for k := range r.indexKeys(pk, old) {
+			if _, found := current[k]; !found {
+				entries = append(entries, &goukv.Entry{Key: []byte(k)})
+			}
+		}
+	}
+
+	for k := range current {
+		entries = append(entries, &goukv.Entry{Key: []byte(k), Value: []byte(pk)})
+	}
+
+	return r.provider.Batch(entries)
+}
+
+// Get fetches the record of the specified primary key
+func (r *Repository[T]) Get(pk interface{}) (*T, error) {
+	data, err := r.provider.Get(r.recordKey(fmt.Sprint(pk)))
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, goukv.ErrKeyNotFound
+	}
+
+	v := new(T)
+	if err := r.codec.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// Delete deletes the record of the specified primary key and its index entries using a single batch
+func (r *Repository[T]) Delete(pk interface{}) error {
+	key := fmt.Sprint(pk)
+
+	v, err := r.Get(key)
+	if err == goukv.ErrKeyNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	entries := []*goukv.Entry{{Key: r.recordKey(key)}}
+	for k := range r.indexKeys(key, v) {
+		entries = append(entries, &goukv.Entry{Key: []byte(k)})
+	}
+
+	return r.provider.Batch(entries)
+}
+
+// FindBy returns the records whose specified indexed field equals the specified value,
+// the index entries are verified against the records, so a stale entry is never returned
+func (r *Repository[T]) FindBy(field string, value interface{}) ([]*T, error) {
+	idx, found := r.indexes[field]
+	if !found {
+		return nil, ErrUnknownIndex
+	}
+
+	expected := fmt.Sprint(value)
+	pks := []string{}
+
+	err := r.provider.Scan(goukv.ScanOpts{
+		Prefix: []byte(r.indexPrefix(field, expected)),
+		Scanner: func(k, v []byte) bool {
+			pks = append(pks, string(v))
+			return true
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*T{}
+
+	for _, pk := range pks {
+		v, err := r.Get(pk)
+		if err == goukv.ErrKeyNotFound || err == goukv.ErrKeyExpired {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if format(reflect.ValueOf(v).Elem().Field(idx)) == expected {
+			result = append(result, v)
+		}
+	}
+
+	return result, nil
+}
+
+// Scan passes the records to the specified scanner until it returns false,
+// the scan stops at the first record that can't be decoded
+func (r *Repository[T]) Scan(scanner func(*T) bool) error {
+	var decodeErr error
+
+	err := r.provider.Scan(goukv.ScanOpts{
+		Prefix: []byte(r.name + ":r:"),
+		Scanner: func(k, data []byte) bool {
+			v := new(T)
+			if err := r.codec.Unmarshal(data, v); err != nil {
+				decodeErr = err
+				return false
+			}
+
+			return scanner(v)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return decodeErr
+}
+
+// recordKey returns the key of the record of the specified primary key
+func (r *Repository[T]) recordKey(pk string) []byte {
+	return []byte(r.name + ":r:" + url.QueryEscape(pk))
+}
+
+// indexPrefix returns the prefix of the index entries of the specified field value
+func (r *Repository[T]) indexPrefix(field, value string) string {
+	return r.name + ":i:" + field + ":" + url.QueryEscape(value) + ":"
+}
+
+// indexKeys returns the index entries keys of the specified record
+func (r *Repository[T]) indexKeys(pk string, v *T) map[string]struct{} {
+	keys := map[string]struct{}{}
+	rv := reflect.ValueOf(v).Elem()
+
+	for field, idx := range r.indexes {
+		f := rv.Field(idx)
+		if f.Kind() == reflect.Ptr && f.IsNil() {
+			continue
+		}
+
+		keys[r.indexPrefix(field, format(f))+url.QueryEscape(pk)] = struct{}{}
+	}
+
+	return keys
+}
+
+// format returns the string form of the specified field value, it is empty for the nil pointers
+func format(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return ""
+	}
+
+	return fmt.Sprint(reflect.Indirect(v).Interface())
+}
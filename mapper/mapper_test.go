@@ -0,0 +1,108 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/internal/testutil"
+)
+
+type user struct {
+	ID      int    `goukv:"pk"`
+	Email   string `goukv:"index"`
+	Country string `goukv:"index"`
+	Name    string
+}
+
+func TestSaveFindBy(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		users, err := New[user](db, "users", goukv.JSONCodec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		users.Save(&user{ID: 1, Email: "a@example.com", Country: "eg"})
+		users.Save(&user{ID: 2, Email: "b@example.com", Country: "eg"})
+		users.Save(&user{ID: 3, Email: "c@example.com", Country: "de:x"})
+
+		found, err := users.FindBy("Country", "eg")
+		if err != nil || len(found) != 2 {
+			t.Fatalf("expected (2) users, found (%d, %v)", len(found), err)
+		}
+
+		// the old email index entry is removed
+		users.Save(&user{ID: 1, Email: "new@example.com", Country: "eg", Name: "someone"})
+
+		if found, _ := users.FindBy("Email", "a@example.com"); len(found) != 0 {
+			t.Errorf("expected the stale index entry to be removed, found %v", found)
+		}
+
+		if found, _ := users.FindBy("Email", "new@example.com"); len(found) != 1 || found[0].Name != "someone" {
+			t.Errorf("expected the updated user, found %v", found)
+		}
+
+		// `de` must not match `de:x`
+		if found, _ := users.FindBy("Country", "de"); len(found) != 0 {
+			t.Errorf("expected no users, found %v", found)
+		}
+
+		if err := users.Delete(2); err != nil {
+			t.Fatal(err)
+		}
+
+		count := 0
+		db.Scan(goukv.ScanOpts{Scanner: func(k, v []byte) bool {
+			count++
+			return true
+		}})
+
+		// 2 records and 2 index entries per record
+		if count != 6 {
+			t.Errorf("expected (6) keys, found (%d)", count)
+		}
+
+		if _, err := users.FindBy("Name", "someone"); err != ErrUnknownIndex {
+			t.Errorf("expected (%v), found (%v)", ErrUnknownIndex, err)
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New[string](nil, "x", goukv.JSONCodec); err != ErrNotStruct {
+		t.Errorf("expected (%v), found (%v)", ErrNotStruct, err)
+	}
+
+	if _, err := New[struct{ Name string }](nil, "x", goukv.JSONCodec); err != ErrNoPrimaryKey {
+		t.Errorf("expected (%v), found (%v)", ErrNoPrimaryKey, err)
+	}
+
+	if _, err := New[struct {
+		ID   int    `goukv:"pk"`
+		name string `goukv:"index"`
+	}](nil, "x", goukv.JSONCodec); err != ErrUnexportedField {
+		t.Errorf("expected (%v), found (%v)", ErrUnexportedField, err)
+	}
+}
+
+func TestNilPrimaryKey(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		type account struct {
+			ID    *string `goukv:"pk"`
+			Owner *string `goukv:"index"`
+		}
+
+		repo, err := New[account](db, "accounts", goukv.JSONCodec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := repo.Save(&account{}); err != ErrEmptyPrimaryKey {
+			t.Errorf("expected (%v), found (%v)", ErrEmptyPrimaryKey, err)
+		}
+
+		id := "1"
+		if err := repo.Save(&account{ID: &id}); err != nil {
+			t.Errorf("expected the nil indexed field to be saved, found (%v)", err)
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package goukv
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryProvider a minimal ordered in-memory provider used by the tests
+type memoryProvider struct {
+	sync.RWMutex
+	data    map[string][]byte
+	expires map[string]time.Time
+	now     func() time.Time
+}
+
+func newMemoryProvider() *memoryProvider {
+	return &memoryProvider{
+		data:    map[string][]byte{},
+		expires: map[string]time.Time{},
+		now:     time.Now,
+	}
+}
+
+// fakeClock a clock that only moves when it is advanced
+type fakeClock struct {
+	sync.Mutex
+	t time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Now()}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.t = c.t.Add(d)
+}
+
+func (m *memoryProvider) Open(*DSN) (Provider, error) {
+	return newMemoryProvider(), nil
+}
+
+func (m *memoryProvider) Put(e *Entry) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.put(e)
+
+	return nil
+}
+
+func (m *memoryProvider) put(e *Entry) {
+	m.data[string(e.Key)] = append([]byte{}, e.Value...)
+	delete(m.expires, string(e.Key))
+
+	if e.TTL > 0 {
+		m.expires[string(e.Key)] = m.now().Add(e.TTL)
+	}
+}
+
+func (m *memoryProvider) Get(k []byte) ([]byte, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	v, ok := m.data[string(k)]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	if x, ok := m.expires[string(k)]; ok && !m.now().Before(x) {
+		return nil, ErrKeyExpired
+	}
+
+	return v, nil
+}
+
+func (m *memoryProvider) TTL(k []byte) (*time.Time, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	if _, ok := m.data[string(k)]; !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	if x, ok := m.expires[string(k)]; ok {
+		return &x, nil
+	}
+
+	return nil, nil
+}
+
+func (m *memoryProvider) Delete(k []byte) error {
+	m.Lock()
+	defer m.Unlock()
+
+	delete(m.data, string(k))
+	delete(m.expires, string(k))
+
+	return nil
+}
+
+func (m *memoryProvider) Batch(entries []*Entry) error {
+	m.Lock()
+	defer m.Unlock()
+
+	for _, e := range entries {
+		if e.Value == nil {
+			delete(m.data, string(e.Key))
+			delete(m.expires, string(e.Key))
+		} else {
+			m.put(e)
+		}
+	}
+
+	return nil
+}
+
+func (m *memoryProvider) CompareAndSwap(e *Entry, old []byte) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	cur, found := m.data[string(e.Key)]
+	if x, ok := m.expires[string(e.Key)]; ok && !m.now().Before(x) {
+		found = false
+	}
+
+	if found != (old != nil) || !bytes.Equal(cur, old) && found {
+		return false, nil
+	}
+
+	if e.Value == nil {
+		delete(m.data, string(e.Key))
+		delete(m.expires, string(e.Key))
+	} else {
+		m.put(e)
+	}
+
+	return true, nil
+}
+
+func (m *memoryProvider) Scan(opts ScanOpts) error {
+	if opts.Scanner == nil {
+		return nil
+	}
+
+	m.RLock()
+	keys := []string{}
+	for k := range m.data {
+		if x, ok := m.expires[k]; ok && !m.now().Before(x) {
+			continue
+		}
+		if bytes.HasPrefix([]byte(k), opts.Prefix) {
+			keys = append(keys, k)
+		}
+	}
+	m.RUnlock()
+
+	sort.Strings(keys)
+	if opts.ReverseScan {
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	}
+
+	for _, k := range keys {
+		if opts.Offset != nil {
+			cmp := bytes.Compare([]byte(k), opts.Offset)
+			if opts.ReverseScan {
+				cmp = -cmp
+			}
+			if cmp < 0 || (cmp == 0 && !opts.IncludeOffset) {
+				continue
+			}
+		}
+
+		m.RLock()
+		v, ok := m.data[k]
+		m.RUnlock()
+
+		if ok && !opts.Scanner([]byte(k), v) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (m *memoryProvider) Close() error {
+	return nil
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/alash3al/goukv"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	name = "metrics"
+)
+
+// Default the collector used by the `metrics` dsn middleware, it is registered to prometheus.DefaultRegisterer
+// on first use (unless it is already registered), and it exposes the native statistics of the opened providers
+var (
+	Default = NewCollector("goukv")
+
+	registerOnce sync.Once
+	registerErr  error
+)
+
+func init() {
+	goukv.RegisterMiddleware(name, func(dsn *goukv.DSN) (goukv.Middleware, error) {
+		registerOnce.Do(func() {
+			registerErr = prometheus.Register(Default)
+
+			if existing, ok := registerErr.(prometheus.AlreadyRegisteredError); ok && existing.ExistingCollector == Default {
+				registerErr = nil
+			}
+		})
+
+		if registerErr != nil {
+			return goukv.Middleware{}, registerErr
+		}
+
+		providerName := dsn.GetString("metrics_name")
+		if providerName == "" {
+			providerName = dsn.Scheme()
+		}
+
+		// the factory is called per opened provider, so each one unwatches its own statistics
+		unwatch := func() {}
+
+		m := Default.Middleware(providerName)
+		m.Opened = func(p goukv.Provider) {
+			unwatch = Default.Watch(providerName, p)
+		}
+		m.Close = unwatchOnClose(func() { unwatch() }).Close
+
+		return m, nil
+	})
+}
@@ -0,0 +1,266 @@
+// Package metrics instruments goukv providers using prometheus.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/internal/scanutil"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// error kinds used as the `kind` label value
+const (
+	KindNotFound = "not_found"
+	KindExpired  = "expired"
+	KindOther    = "other"
+)
+
+// Collector collects the metrics of the instrumented providers, it implements prometheus.Collector
+type Collector struct {
+	operations *prometheus.CounterVec
+	errors     *prometheus.CounterVec
+	latency    *prometheus.HistogramVec
+	scanItems  *prometheus.HistogramVec
+	batchSize  *prometheus.HistogramVec
+	stats      *prometheus.Desc
+
+	watched     map[uint64]watched
+	watchedID   uint64
+	watchedLock sync.RWMutex
+}
+
+// watched a provider whose native statistics are exposed
+type watched struct {
+	name     string
+	provider goukv.Provider
+}
+
+// NewCollector initializes a new collector, all of its metrics are prefixed by the specified namespace
+func NewCollector(namespace string) *Collector {
+	return &Collector{
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "operations_total",
+			Help:      "The number of operations performed",
+		}, []string{"provider", "operation"}),
+
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "operation_errors_total",
+			Help:      "The number of failed operations by error kind",
+		}, []string{"provider", "operation", "kind"}),
+
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "operation_duration_seconds",
+			Help:      "The operations latency",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+		}, []string{"provider", "operation"}),
+
+		scanItems: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "scan_items",
+			Help:      "The number of items passed to the scanner per scan",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+		}, []string{"provider"}),
+
+		batchSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "batch_size",
+			Help:      "The number of entries per batch",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+		}, []string{"provider"}),
+
+		stats: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "provider_stat"),
+			"The provider native statistics",
+			[]string{"provider", "stat"},
+			nil,
+		),
+
+		watched: map[uint64]watched{},
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.operations.Describe(ch)
+	c.errors.Describe(ch)
+	c.latency.Describe(ch)
+	c.scanItems.Describe(ch)
+	c.batchSize.Describe(ch)
+
+	ch <- c.stats
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.operations.Collect(ch)
+	c.errors.Collect(ch)
+	c.latency.Collect(ch)
+	c.scanItems.Collect(ch)
+	c.batchSize.Collect(ch)
+
+	c.watchedLock.RLock()
+	defer c.watchedLock.RUnlock()
+
+	// the statistics of the providers sharing the same name are summed
+	stats := map[string]map[string]float64{}
+	for _, w := range c.watched {
+		if stats[w.name] == nil {
+			stats[w.name] = map[string]float64{}
+		}
+
+		for stat, val := range goukv.Stats(w.provider) {
+			stats[w.name][stat] += val
+		}
+	}
+
+	for name, values := range stats {
+		for stat, val := range values {
+			ch <- prometheus.MustNewConstMetric(c.stats, prometheus.GaugeValue, val, name, stat)
+		}
+	}
+}
+
+// Watch exposes the native statistics of the specified provider (see goukv.Stater) as gauges labelled by
+// the specified name, the statistics of the providers sharing the same name are summed, it returns a function
+// that stops exposing the statistics of that provider only
+func (c *Collector) Watch(providerName string, p goukv.Provider) (unwatch func()) {
+	c.watchedLock.Lock()
+	defer c.watchedLock.Unlock()
+
+	c.watchedID++
+	id := c.watchedID
+
+	c.watched[id] = watched{name: providerName, provider: p}
+
+	return func() {
+		c.watchedLock.Lock()
+		defer c.watchedLock.Unlock()
+
+		delete(c.watched, id)
+	}
+}
+
+// Instrument wraps the specified provider using the collector middleware and watches its native statistics
+// until it is closed
+func (c *Collector) Instrument(providerName string, p goukv.Provider) goukv.Provider {
+	return goukv.Wrap(p, c.Middleware(providerName), unwatchOnClose(c.Watch(providerName, p)))
+}
+
+// Middleware returns a middleware that records the operations of the provider labelled by the specified name
+func (c *Collector) Middleware(providerName string) goukv.Middleware {
+	return goukv.Middleware{
+		Put: func(next goukv.PutFunc) goukv.PutFunc {
+			return func(e *goukv.Entry) error {
+				start := time.Now()
+				err := next(e)
+				c.observe(providerName, "put", start, err)
+
+				return err
+			}
+		},
+
+		Get: func(next goukv.GetFunc) goukv.GetFunc {
+			return func(k []byte) ([]byte, error) {
+				start := time.Now()
+				val, err := next(k)
+				c.observe(providerName, "get", start, err)
+
+				return val, err
+			}
+		},
+
+		TTL: func(next goukv.TTLFunc) goukv.TTLFunc {
+			return func(k []byte) (*time.Time, error) {
+				start := time.Now()
+				t, err := next(k)
+				c.observe(providerName, "ttl", start, err)
+
+				return t, err
+			}
+		},
+
+		Delete: func(next goukv.DeleteFunc) goukv.DeleteFunc {
+			return func(k []byte) error {
+				start := time.Now()
+				err := next(k)
+				c.observe(providerName, "delete", start, err)
+
+				return err
+			}
+		},
+
+		Batch: func(next goukv.BatchFunc) goukv.BatchFunc {
+			return func(entries []*goukv.Entry) error {
+				start := time.Now()
+				err := next(entries)
+				c.observe(providerName, "batch", start, err)
+				c.batchSize.WithLabelValues(providerName).Observe(float64(len(entries)))
+
+				return err
+			}
+		},
+
+		Scan: func(next goukv.ScanFunc) goukv.ScanFunc {
+			return func(opts goukv.ScanOpts) error {
+				items := scanutil.Count(&opts)
+
+				start := time.Now()
+				err := next(opts)
+				c.observe(providerName, "scan", start, err)
+				c.scanItems.WithLabelValues(providerName).Observe(float64(*items))
+
+				return err
+			}
+		},
+
+		CompareAndSwap: func(next goukv.CompareAndSwapFunc) goukv.CompareAndSwapFunc {
+			return func(e *goukv.Entry, old []byte) (bool, error) {
+				start := time.Now()
+				swapped, err := next(e, old)
+				c.observe(providerName, "compare_and_swap", start, err)
+
+				return swapped, err
+			}
+		},
+	}
+}
+
+// unwatchOnClose returns a middleware that calls the specified unwatch function once the provider is closed
+func unwatchOnClose(unwatch func()) goukv.Middleware {
+	return goukv.Middleware{
+		Close: func(next goukv.CloseFunc) goukv.CloseFunc {
+			return func() error {
+				unwatch()
+
+				return next()
+			}
+		},
+	}
+}
+
+// observe records the result of an operation
+func (c *Collector) observe(providerName, operation string, start time.Time, err error) {
+	c.operations.WithLabelValues(providerName, operation).Inc()
+	c.latency.WithLabelValues(providerName, operation).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		c.errors.WithLabelValues(providerName, operation, ErrorKind(err)).Inc()
+	}
+}
+
+// ErrorKind returns the `kind` label value of the specified error
+func ErrorKind(err error) string {
+	switch err {
+	case goukv.ErrKeyNotFound:
+		return KindNotFound
+	case goukv.ErrKeyExpired:
+		return KindExpired
+	default:
+		return KindOther
+	}
+}
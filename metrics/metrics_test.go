@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/providers/leveldb"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrument(t *testing.T) {
+	dsn, _ := goukv.NewDSN("leveldb://" + t.TempDir())
+	p, err := leveldb.Provider{}.Open(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCollector("goukv")
+	db := c.Instrument("leveldb", p)
+
+	db.Batch([]*goukv.Entry{
+		{Key: []byte("k1"), Value: []byte("v1")},
+		{Key: []byte("k2"), Value: []byte("v2")},
+	})
+	db.Get([]byte("k1"))
+	db.TTL([]byte("not-found"))
+	db.Scan(goukv.ScanOpts{Scanner: func(k, v []byte) bool { return true }})
+
+	if val := testutil.ToFloat64(c.operations.WithLabelValues("leveldb", "get")); val != 1 {
+		t.Errorf("expected (1) get operation, found (%v)", val)
+	}
+
+	if val := testutil.ToFloat64(c.errors.WithLabelValues("leveldb", "ttl", KindNotFound)); val != 1 {
+		t.Errorf("expected (1) not found error, found (%v)", val)
+	}
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(c)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := map[string]bool{}
+	for _, family := range families {
+		found[family.GetName()] = true
+	}
+
+	for _, name := range []string{"goukv_scan_items", "goukv_batch_size", "goukv_operation_duration_seconds", "goukv_provider_stat"} {
+		if !found[name] {
+			t.Errorf("expected (%s) to be collected", name)
+		}
+	}
+
+	if stats := goukv.Stats(db); len(stats) < 1 {
+		t.Error("expected the native stats to be reachable through the wrapper")
+	}
+
+	db.Close()
+}
+
+func TestDSNMiddleware(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := goukv.Open("leveldb", "leveldb://"+dir+"?middlewares=metrics&metrics_name=dsn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.Put(&goukv.Entry{Key: []byte("k"), Value: []byte("v")})
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := 0
+	for _, family := range families {
+		if family.GetName() != "goukv_provider_stat" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "provider" && label.GetValue() == "dsn" {
+					stats++
+				}
+			}
+		}
+	}
+
+	if stats < 1 {
+		t.Error("expected the native stats of the opened provider to be exposed")
+	}
+
+	db.Close()
+
+	// the collector is registered once, the later opens reuse it
+	db, err = goukv.Open("leveldb", "leveldb://"+dir+"?middlewares=metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.Close()
+}
+
+func TestSameNameProviders(t *testing.T) {
+	open := func() goukv.Provider {
+		dsn, _ := goukv.NewDSN("leveldb://" + t.TempDir())
+		p, err := leveldb.Provider{}.Open(dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return p
+	}
+
+	c := NewCollector("goukv")
+	first := c.Instrument("leveldb", open())
+	second := c.Instrument("leveldb", open())
+	defer second.Close()
+
+	if _, err := goukv.CompareAndSwap(second, &goukv.Entry{Key: []byte("k"), Value: []byte("v")}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if val := testutil.ToFloat64(c.operations.WithLabelValues("leveldb", "compare_and_swap")); val != 1 {
+		t.Errorf("expected (1) compare_and_swap operation, found (%v)", val)
+	}
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(c)
+
+	if _, err := registry.Gather(); err != nil {
+		t.Fatal(err)
+	}
+
+	first.Close()
+
+	if stats := testutil.CollectAndCount(c, "goukv_provider_stat"); stats < 1 {
+		t.Error("expected the stats of the provider that is still open to be exposed")
+	}
+}
@@ -0,0 +1,235 @@
+package goukv
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// middlewares a registry for the middlewares that could be enabled using the dsn
+var (
+	middlewaresMap  = map[string]MiddlewareFactory{}
+	middlewaresLock = &sync.RWMutex{}
+)
+
+// operation handlers, each one represents a Provider method
+type (
+	PutFunc    func(*Entry) error
+	GetFunc    func([]byte) ([]byte, error)
+	TTLFunc    func([]byte) (*time.Time, error)
+	DeleteFunc func([]byte) error
+	BatchFunc  func([]*Entry) error
+	ScanFunc   func(ScanOpts) error
+	CloseFunc  func() error
+
+	CompareAndSwapFunc func(e *Entry, old []byte) (bool, error)
+)
+
+// Middleware intercepts the operations of a provider, each hook receives the next
+// handler in the chain and returns a handler that may run code before and/or after
+// calling it, nil hooks are skipped.
+type Middleware struct {
+	Put    func(next PutFunc) PutFunc
+	Get    func(next GetFunc) GetFunc
+	TTL    func(next TTLFunc) TTLFunc
+	Delete func(next DeleteFunc) DeleteFunc
+	Batch  func(next BatchFunc) BatchFunc
+	Scan   func(next ScanFunc) ScanFunc
+	Close  func(next CloseFunc) CloseFunc
+
+	CompareAndSwap func(next CompareAndSwapFunc) CompareAndSwapFunc
+
+	// Opened is called by goukv.Open with the opened provider before it is wrapped, i.e: to expose its native
+	// statistics, as the middlewares enabled using the dsn can't reach it otherwise
+	Opened func(Provider)
+}
+
+// MiddlewareFactory builds a middleware using the options of the specified dsn
+type MiddlewareFactory func(*DSN) (Middleware, error)
+
+// wrapped a provider decorated by a middleware chain
+type wrapped struct {
+	provider    Provider
+	middlewares []Middleware
+
+	put    PutFunc
+	get    GetFunc
+	ttl    TTLFunc
+	delete DeleteFunc
+	batch  BatchFunc
+	scan   ScanFunc
+	close  CloseFunc
+	cas    CompareAndSwapFunc
+}
+
+// Wrap decorates the specified provider with the specified middlewares,
+// the first middleware is the outermost one, i.e: it is the first to be called.
+func Wrap(p Provider, middlewares ...Middleware) Provider {
+	w := &wrapped{
+		provider:    p,
+		middlewares: middlewares,
+
+		put:    p.Put,
+		get:    p.Get,
+		ttl:    p.TTL,
+		delete: p.Delete,
+		batch:  p.Batch,
+		scan:   p.Scan,
+		close:  p.Close,
+		cas: func(e *Entry, old []byte) (bool, error) {
+			return CompareAndSwap(p, e, old)
+		},
+	}
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		m := middlewares[i]
+
+		if m.Put != nil {
+			w.put = m.Put(w.put)
+		}
+
+		if m.Get != nil {
+			w.get = m.Get(w.get)
+		}
+
+		if m.TTL != nil {
+			w.ttl = m.TTL(w.ttl)
+		}
+
+		if m.Delete != nil {
+			w.delete = m.Delete(w.delete)
+		}
+
+		if m.Batch != nil {
+			w.batch = m.Batch(w.batch)
+		}
+
+		if m.Scan != nil {
+			w.scan = m.Scan(w.scan)
+		}
+
+		if m.Close != nil {
+			w.close = m.Close(w.close)
+		}
+
+		if m.CompareAndSwap != nil {
+			w.cas = m.CompareAndSwap(w.cas)
+		}
+	}
+
+	return w
+}
+
+// Open implements goukv.Open, the opened provider is wrapped by the same middlewares
+func (w *wrapped) Open(dsn *DSN) (Provider, error) {
+	p, err := w.provider.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return Wrap(p, w.middlewares...), nil
+}
+
+// Unwrap implements goukv.Unwrapper
+func (w *wrapped) Unwrap() Provider {
+	return w.provider
+}
+
+// WithContext implements goukv.ContextBinder, the context is bound to the wrapped provider
+func (w *wrapped) WithContext(ctx context.Context) Provider {
+	return Wrap(WithContext(ctx, w.provider), w.middlewares...)
+}
+
+// Put implements goukv.Put
+func (w *wrapped) Put(e *Entry) error {
+	return w.put(e)
+}
+
+// Get implements goukv.Get
+func (w *wrapped) Get(k []byte) ([]byte, error) {
+	return w.get(k)
+}
+
+// TTL implements goukv.TTL
+func (w *wrapped) TTL(k []byte) (*time.Time, error) {
+	return w.ttl(k)
+}
+
+// Delete implements goukv.Delete
+func (w *wrapped) Delete(k []byte) error {
+	return w.delete(k)
+}
+
+// Batch implements goukv.Batch
+func (w *wrapped) Batch(entries []*Entry) error {
+	return w.batch(entries)
+}
+
+// Scan implements goukv.Scan
+func (w *wrapped) Scan(opts ScanOpts) error {
+	return w.scan(opts)
+}
+
+// Close implements goukv.Close
+func (w *wrapped) Close() error {
+	return w.close()
+}
+
+// CompareAndSwap implements goukv.CompareAndSwapper
+func (w *wrapped) CompareAndSwap(e *Entry, old []byte) (bool, error) {
+	return w.cas(e, old)
+}
+
+// RegisterMiddleware register a new middleware factory, so it could be enabled using
+// the `middlewares` dsn option, i.e: `?middlewares=logger,metrics`
+func RegisterMiddleware(name string, factory MiddlewareFactory) error {
+	middlewaresLock.Lock()
+	defer middlewaresLock.Unlock()
+
+	if middlewaresMap[name] != nil {
+		return ErrMiddlewareAlreadyExists
+	}
+
+	middlewaresMap[name] = factory
+
+	return nil
+}
+
+// GetMiddleware returns a middleware factory from the registery
+func GetMiddleware(name string) (MiddlewareFactory, error) {
+	middlewaresLock.RLock()
+	defer middlewaresLock.RUnlock()
+
+	if middlewaresMap[name] == nil {
+		return nil, ErrMiddlewareNotFound
+	}
+
+	return middlewaresMap[name], nil
+}
+
+// middlewaresFromDSN builds the middlewares listed in the `middlewares` dsn option
+func middlewaresFromDSN(dsn *DSN) ([]Middleware, error) {
+	middlewares := []Middleware{}
+
+	for _, name := range strings.Split(dsn.GetString("middlewares"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		factory, err := GetMiddleware(name)
+		if err != nil {
+			return nil, err
+		}
+
+		m, err := factory(dsn)
+		if err != nil {
+			return nil, err
+		}
+
+		middlewares = append(middlewares, m)
+	}
+
+	return middlewares, nil
+}
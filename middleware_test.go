@@ -0,0 +1,126 @@
+package goukv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func recorder(name string, calls *[]string) Middleware {
+	return Middleware{
+		Put: func(next PutFunc) PutFunc {
+			return func(e *Entry) error {
+				*calls = append(*calls, "before:"+name)
+				err := next(e)
+				*calls = append(*calls, "after:"+name)
+				return err
+			}
+		},
+	}
+}
+
+func TestWrapOrder(t *testing.T) {
+	calls := []string{}
+	db := Wrap(newMemoryProvider(), recorder("a", &calls), recorder("b", &calls))
+
+	if err := db.Put(&Entry{Key: []byte("k"), Value: []byte("v")}); err != nil {
+		t.Error(err)
+	}
+
+	expected := "before:a,before:b,after:b,after:a"
+	if found := strings.Join(calls, ","); found != expected {
+		t.Errorf("expected (%s), found (%s)", expected, found)
+	}
+
+	// operations without hooks reach the provider as is
+	val, err := db.Get([]byte("k"))
+	if err != nil || string(val) != "v" {
+		t.Errorf("expected (v), found (%s, %v)", string(val), err)
+	}
+}
+
+func TestWrapShortCircuit(t *testing.T) {
+	errDenied := errors.New("denied")
+	db := Wrap(newMemoryProvider(), Middleware{
+		Delete: func(next DeleteFunc) DeleteFunc {
+			return func(k []byte) error {
+				return errDenied
+			}
+		},
+	})
+
+	db.Put(&Entry{Key: []byte("k"), Value: []byte("v")})
+
+	if err := db.Delete([]byte("k")); err != errDenied {
+		t.Errorf("expected (%v), found (%v)", errDenied, err)
+	}
+
+	if _, err := db.Get([]byte("k")); err != nil {
+		t.Errorf("expected the key to be kept, found (%v)", err)
+	}
+}
+
+func TestOpenWithMiddlewares(t *testing.T) {
+	calls := []string{}
+
+	registerProvider(t, "memory-middlewares", newMemoryProvider())
+	registerMiddleware(t, "recorder", func(dsn *DSN) (Middleware, error) {
+		return recorder(dsn.GetString("recorder_name"), &calls), nil
+	})
+
+	db, err := Open("memory-middlewares", "memory://?middlewares=recorder&recorder_name=x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.Put(&Entry{Key: []byte("k"), Value: []byte("v")})
+
+	if found := strings.Join(calls, ","); found != "before:x,after:x" {
+		t.Errorf("expected (before:x,after:x), found (%s)", found)
+	}
+
+	registerMiddleware(t, "opened", func(dsn *DSN) (Middleware, error) {
+		return Middleware{Opened: func(p Provider) { calls = append(calls, "opened") }}, nil
+	})
+
+	calls = nil
+	if _, err := Open("memory-middlewares", "memory://?middlewares=opened"); err != nil || len(calls) != 1 {
+		t.Errorf("expected the opened hook to be called once, found (%v, %v)", calls, err)
+	}
+
+	if _, err := Open("memory-middlewares", "memory://?middlewares=unknown"); err != ErrMiddlewareNotFound {
+		t.Errorf("expected (%v), found (%v)", ErrMiddlewareNotFound, err)
+	}
+}
+
+// registerProvider registers the specified provider and removes it from the registry once the test is done
+func registerProvider(t *testing.T, name string, p Provider) {
+	t.Helper()
+
+	if err := Register(name, p); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		providersLock.Lock()
+		defer providersLock.Unlock()
+
+		delete(providersMap, name)
+	})
+}
+
+// registerMiddleware registers the specified middleware factory and removes it from the registry once the test is done
+func registerMiddleware(t *testing.T, name string, factory MiddlewareFactory) {
+	t.Helper()
+
+	if err := RegisterMiddleware(name, factory); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		middlewaresLock.Lock()
+		defer middlewaresLock.Unlock()
+
+		delete(middlewaresMap, name)
+	})
+}
@@ -0,0 +1,301 @@
+package goukv
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	namespaceSeparator = '\x1f'
+	namespaceDropBatch = 1000
+)
+
+// namespacesRegistry the prefix of the keys registering the namespaces,
+// it can't clash with the namespaced keys as the names can't contain the separator
+var namespacesRegistry = []byte{namespaceSeparator, namespaceSeparator}
+
+// Namespacer an optional interface implemented by the providers that support namespaces natively,
+// i.e: by storing each namespace in its own table
+type Namespacer interface {
+	Namespace(name string) (Provider, error)
+	Namespaces() ([]string, error)
+	DropNamespace(name string) error
+}
+
+// Namespace returns a provider confined to the specified namespace of the specified provider,
+// the keys are transparently prefixed by `\x1f<name>\x1f` unless the provider implements goukv.Namespacer,
+// so the name must not contain `\x1f`. closing the returned provider doesn't close the specified one,
+// and an invalid namespace is reported by all of its operations.
+func Namespace(p Provider, name string) Provider {
+	if namespacer, ok := p.(Namespacer); ok {
+		ns, err := namespacer.Namespace(name)
+		if err != nil {
+			return failedProvider{err: err}
+		}
+
+		return ns
+	}
+
+	if !validNamespace(name) {
+		return failedProvider{err: ErrInvalidNamespace}
+	}
+
+	return &namespaced{
+		provider:   p,
+		name:       name,
+		prefix:     namespacePrefix(name),
+		registered: &namespaceRegistration{},
+	}
+}
+
+// Namespaces lists the namespaces of the specified provider
+func Namespaces(p Provider) ([]string, error) {
+	if namespacer, ok := p.(Namespacer); ok {
+		return namespacer.Namespaces()
+	}
+
+	names := []string{}
+
+	err := p.Scan(ScanOpts{
+		Prefix: namespacesRegistry,
+		Scanner: func(k, v []byte) bool {
+			names = append(names, string(k[len(namespacesRegistry):]))
+			return true
+		},
+	})
+
+	return names, err
+}
+
+// DropNamespace deletes all the keys of the specified namespace of the specified provider
+func DropNamespace(p Provider, name string) error {
+	if namespacer, ok := p.(Namespacer); ok {
+		return namespacer.DropNamespace(name)
+	}
+
+	if !validNamespace(name) {
+		return ErrInvalidNamespace
+	}
+
+	prefix := namespacePrefix(name)
+
+	// the deleted keys aren't scanned again, so each page starts from the beginning of the namespace
+	for {
+		entries := []*Entry{}
+
+		err := p.Scan(ScanOpts{
+			Prefix: prefix,
+			Scanner: func(k, v []byte) bool {
+				entries = append(entries, &Entry{Key: append([]byte{}, k...)})
+				return len(entries) < namespaceDropBatch
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(entries) < 1 {
+			break
+		}
+
+		if err := p.Batch(entries); err != nil {
+			return err
+		}
+	}
+
+	return p.Delete(append(append([]byte{}, namespacesRegistry...), name...))
+}
+
+// namespaceRegistration whether the namespace has been registered by a namespaced provider
+type namespaceRegistration struct {
+	sync.Mutex
+	done bool
+}
+
+// namespaced a provider confined to a namespace of another provider
+type namespaced struct {
+	provider   Provider
+	name       string
+	prefix     []byte
+	registered *namespaceRegistration
+}
+
+// Unwrap implements goukv.Unwrapper
+func (n *namespaced) Unwrap() Provider {
+	return n.provider
+}
+
+// WithContext implements goukv.ContextBinder
+func (n *namespaced) WithContext(ctx context.Context) Provider {
+	return &namespaced{
+		provider:   WithContext(ctx, n.provider),
+		name:       n.name,
+		prefix:     n.prefix,
+		registered: n.registered,
+	}
+}
+
+// Open implements goukv.Open
+func (n *namespaced) Open(dsn *DSN) (Provider, error) {
+	p, err := n.provider.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return Namespace(p, n.name), nil
+}
+
+// Put implements goukv.Put
+func (n *namespaced) Put(e *Entry) error {
+	if err := n.register(); err != nil {
+		return err
+	}
+
+	return n.provider.Put(&Entry{Key: n.key(e.Key), Value: e.Value, TTL: e.TTL})
+}
+
+// Get implements goukv.Get
+func (n *namespaced) Get(k []byte) ([]byte, error) {
+	return n.provider.Get(n.key(k))
+}
+
+// TTL implements goukv.TTL
+func (n *namespaced) TTL(k []byte) (*time.Time, error) {
+	return n.provider.TTL(n.key(k))
+}
+
+// Delete implements goukv.Delete
+func (n *namespaced) Delete(k []byte) error {
+	return n.provider.Delete(n.key(k))
+}
+
+// Batch implements goukv.Batch
+func (n *namespaced) Batch(entries []*Entry) error {
+	if err := n.register(); err != nil {
+		return err
+	}
+
+	prefixed := make([]*Entry, 0, len(entries))
+	for _, entry := range entries {
+		prefixed = append(prefixed, &Entry{Key: n.key(entry.Key), Value: entry.Value, TTL: entry.TTL})
+	}
+
+	return n.provider.Batch(prefixed)
+}
+
+// CompareAndSwap implements goukv.CompareAndSwapper
+func (n *namespaced) CompareAndSwap(e *Entry, old []byte) (bool, error) {
+	if err := n.register(); err != nil {
+		return false, err
+	}
+
+	return CompareAndSwap(n.provider, &Entry{Key: n.key(e.Key), Value: e.Value, TTL: e.TTL}, old)
+}
+
+// Scan implements goukv.Scan
+func (n *namespaced) Scan(opts ScanOpts) error {
+	opts.Prefix = n.key(opts.Prefix)
+
+	if opts.Offset != nil {
+		opts.Offset = n.key(opts.Offset)
+	}
+
+	if scanner := opts.Scanner; scanner != nil {
+		opts.Scanner = func(k, v []byte) bool {
+			return scanner(k[len(n.prefix):], v)
+		}
+	}
+
+	return n.provider.Scan(opts)
+}
+
+// Watch implements goukv.Watcher, the changes are watched under the namespace prefix of the provider it wraps
+func (n *namespaced) Watch(ctx context.Context, prefix []byte) (<-chan []byte, error) {
+	changes, err := Watch(ctx, n.provider, n.key(prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(chan []byte)
+
+	go func() {
+		defer close(keys)
+
+		for k := range changes {
+			select {
+			case keys <- k[len(n.prefix):]:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return keys, nil
+}
+
+// Close implements goukv.Close, it is a no-op as the provider is shared by all the namespaces
+func (n *namespaced) Close() error {
+	return nil
+}
+
+// key returns the prefixed form of the specified key
+func (n *namespaced) key(k []byte) []byte {
+	key := make([]byte, 0, len(n.prefix)+len(k))
+	key = append(key, n.prefix...)
+
+	return append(key, k...)
+}
+
+// register registers the namespace once, so it could be listed without scanning all the keys,
+// a namespace dropped while a provider created before dropping it is still in use isn't registered again
+func (n *namespaced) register() error {
+	n.registered.Lock()
+	defer n.registered.Unlock()
+
+	if n.registered.done {
+		return nil
+	}
+
+	err := n.provider.Put(&Entry{
+		Key:   append(append([]byte{}, namespacesRegistry...), n.name...),
+		Value: []byte(n.name),
+	})
+	if err != nil {
+		return err
+	}
+
+	n.registered.done = true
+
+	return nil
+}
+
+// namespacePrefix returns the keys prefix of the specified namespace
+func namespacePrefix(name string) []byte {
+	prefix := make([]byte, 0, len(name)+2)
+	prefix = append(prefix, namespaceSeparator)
+	prefix = append(prefix, name...)
+
+	return append(prefix, namespaceSeparator)
+}
+
+// validNamespace whether the specified namespace name is valid
+func validNamespace(name string) bool {
+	return name != "" && bytes.IndexByte([]byte(name), namespaceSeparator) < 0
+}
+
+// failedProvider a provider that fails all of its operations by the same error
+type failedProvider struct {
+	err error
+}
+
+func (f failedProvider) Open(*DSN) (Provider, error)                 { return nil, f.err }
+func (f failedProvider) Put(*Entry) error                            { return f.err }
+func (f failedProvider) Get([]byte) ([]byte, error)                  { return nil, f.err }
+func (f failedProvider) TTL([]byte) (*time.Time, error)              { return nil, f.err }
+func (f failedProvider) Delete([]byte) error                         { return f.err }
+func (f failedProvider) Batch([]*Entry) error                        { return f.err }
+func (f failedProvider) Scan(ScanOpts) error                         { return f.err }
+func (f failedProvider) Close() error                                { return f.err }
+func (f failedProvider) CompareAndSwap(*Entry, []byte) (bool, error) { return false, f.err }
@@ -0,0 +1,76 @@
+package goukv
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestNamespaceIsolation(t *testing.T) {
+	db := newMemoryProvider()
+	a, ab := Namespace(db, "a"), Namespace(db, "ab")
+
+	a.Put(&Entry{Key: []byte("k1"), Value: []byte("a1")})
+	ab.Batch([]*Entry{
+		{Key: []byte("k1"), Value: []byte("ab1")},
+		{Key: []byte("k2"), Value: []byte("ab2")},
+	})
+
+	if val, err := a.Get([]byte("k1")); err != nil || string(val) != "a1" {
+		t.Errorf("expected (a1), found (%s, %v)", string(val), err)
+	}
+
+	if _, err := a.Get([]byte("k2")); err != ErrKeyNotFound {
+		t.Errorf("expected (%v), found (%v)", ErrKeyNotFound, err)
+	}
+
+	found := []string{}
+	ab.Scan(ScanOpts{Scanner: func(k, v []byte) bool {
+		found = append(found, string(k)+"="+string(v))
+		return true
+	}})
+
+	if strings.Join(found, ",") != "k1=ab1,k2=ab2" {
+		t.Errorf("expected the scan to be confined to the namespace, found %v", found)
+	}
+
+	names, err := Namespaces(db)
+	sort.Strings(names)
+	if err != nil || strings.Join(names, ",") != "a,ab" {
+		t.Errorf("expected (a, ab), found (%v, %v)", names, err)
+	}
+}
+
+func TestDropNamespace(t *testing.T) {
+	db := newMemoryProvider()
+	a, b := Namespace(db, "a"), Namespace(db, "b")
+
+	for i := 0; i < namespaceDropBatch+10; i++ {
+		a.Put(&Entry{Key: []byte{byte(i >> 8), byte(i)}, Value: []byte("v")})
+	}
+	b.Put(&Entry{Key: []byte("k"), Value: []byte("v")})
+	db.Put(&Entry{Key: []byte("global"), Value: []byte("v")})
+
+	if err := DropNamespace(db, "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	db.Scan(ScanOpts{Scanner: func(k, v []byte) bool {
+		count++
+		return true
+	}})
+
+	// b's key and registration plus the global key
+	if count != 3 {
+		t.Errorf("expected (3) keys to be kept, found (%d)", count)
+	}
+
+	if names, _ := Namespaces(db); len(names) != 1 || names[0] != "b" {
+		t.Errorf("expected (b), found %v", names)
+	}
+
+	if err := Namespace(db, "in\x1fvalid").Put(&Entry{Key: []byte("k"), Value: []byte("v")}); err != ErrInvalidNamespace {
+		t.Errorf("expected (%v), found (%v)", ErrInvalidNamespace, err)
+	}
+}
@@ -1,6 +1,7 @@
 package goukv
 
 import (
+	"context"
 	"sync"
 	"time"
 )
@@ -23,6 +24,45 @@ type Provider interface {
 	Close() error
 }
 
+// Stater an optional interface implemented by the providers that expose
+// their internal statistics, i.e: storage sizes, connections pool stats ... etc
+type Stater interface {
+	Stats() map[string]float64
+}
+
+// Unwrapper an optional interface implemented by the providers that decorate another provider
+type Unwrapper interface {
+	Unwrap() Provider
+}
+
+// ContextBinder an optional interface implemented by the providers that accept a context
+// for their operations, i.e: for cancellation and tracing
+type ContextBinder interface {
+	WithContext(context.Context) Provider
+}
+
+// OrderedScanner an optional interface implemented by the providers whose scans follow the keys
+// byte order and honor the offsets, so they could serve range queries
+type OrderedScanner interface {
+	ScansOrdered() bool
+}
+
+// Watcher an optional interface implemented by the providers that could notify about the changes,
+// the keys changed under the specified prefix are sent to the returned channel until the specified
+// context is done, then the channel is closed. use goukv.Watch to reach it through the wrappers
+type Watcher interface {
+	Watch(ctx context.Context, prefix []byte) (<-chan []byte, error)
+}
+
+// CompareAndSwapper an optional interface implemented by the providers that support conditional writes,
+// the specified entry is written (a nil value means delete) only if the current value of its key equals
+// the specified old one, a nil old value means that the key must not exist or must be expired.
+// it reports whether the entry has been written. the wrappers (i.e: goukv.Wrap, goukv.Namespace) implement it
+// by delegating to the provider they wrap, so they fail with ErrCompareAndSwapUnsupported if it doesn't
+type CompareAndSwapper interface {
+	CompareAndSwap(e *Entry, old []byte) (bool, error)
+}
+
 // Register register a new driver
 func Register(name string, provider Provider) error {
 	providersLock.Lock()
@@ -49,7 +89,8 @@ func Get(providerName string) (Provider, error) {
 	return providersMap[providerName], nil
 }
 
-// Open initialize the specified provider and returns its instance
+// Open initialize the specified provider and returns its instance,
+// the instance is wrapped by the middlewares listed in the `middlewares` dsn option if any
 func Open(providerName, dsn string) (Provider, error) {
 	dsnParsed, err := NewDSN(dsn)
 	if err != nil {
@@ -61,5 +102,122 @@ func Open(providerName, dsn string) (Provider, error) {
 		return nil, err
 	}
 
-	return providerInterface.Open(dsnParsed)
+	middlewares, err := middlewaresFromDSN(dsnParsed)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := providerInterface.Open(dsnParsed)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(middlewares) < 1 {
+		return p, nil
+	}
+
+	for _, m := range middlewares {
+		if m.Opened != nil {
+			m.Opened(p)
+		}
+	}
+
+	return Wrap(p, middlewares...), nil
+}
+
+// Stats returns the internal statistics of the specified provider (or the provider it wraps),
+// nil is returned if it doesn't implement goukv.Stater
+func Stats(p Provider) map[string]float64 {
+	for p != nil {
+		if stater, ok := p.(Stater); ok {
+			return stater.Stats()
+		}
+
+		unwrapper, ok := p.(Unwrapper)
+		if !ok {
+			break
+		}
+
+		p = unwrapper.Unwrap()
+	}
+
+	return nil
+}
+
+// WithContext binds the specified context to the specified provider if it implements goukv.ContextBinder,
+// otherwise the provider is returned as is
+func WithContext(ctx context.Context, p Provider) Provider {
+	if binder, ok := p.(ContextBinder); ok {
+		return binder.WithContext(ctx)
+	}
+
+	return p
+}
+
+// CompareAndSwap performs a conditional write (see goukv.CompareAndSwapper) using the specified provider,
+// ErrCompareAndSwapUnsupported is returned if it doesn't implement goukv.CompareAndSwapper
+func CompareAndSwap(p Provider, e *Entry, old []byte) (bool, error) {
+	cas, ok := p.(CompareAndSwapper)
+	if !ok {
+		return false, ErrCompareAndSwapUnsupported
+	}
+
+	return cas.CompareAndSwap(e, old)
+}
+
+// SupportsCompareAndSwap whether the specified provider and all the providers it wraps implement
+// goukv.CompareAndSwapper, so its conditional writes won't fail with ErrCompareAndSwapUnsupported
+func SupportsCompareAndSwap(p Provider) bool {
+	for p != nil {
+		if _, ok := p.(CompareAndSwapper); !ok {
+			return false
+		}
+
+		unwrapper, ok := p.(Unwrapper)
+		if !ok {
+			return true
+		}
+
+		p = unwrapper.Unwrap()
+	}
+
+	return false
+}
+
+// Watch watches the changes of the specified provider (or the provider it wraps, see goukv.Watcher) under the
+// specified prefix, ErrWatchUnsupported is returned if none of them implements goukv.Watcher
+func Watch(ctx context.Context, p Provider, prefix []byte) (<-chan []byte, error) {
+	for p != nil {
+		if watcher, ok := p.(Watcher); ok {
+			return watcher.Watch(ctx, prefix)
+		}
+
+		unwrapper, ok := p.(Unwrapper)
+		if !ok {
+			break
+		}
+
+		p = unwrapper.Unwrap()
+	}
+
+	return nil, ErrWatchUnsupported
+}
+
+// ScansOrdered whether the scans of the specified provider (or the provider it wraps) follow the keys
+// byte order, false is returned if none of them implements goukv.OrderedScanner
+func ScansOrdered(p Provider) bool {
+	for p != nil {
+		if orderer, ok := p.(OrderedScanner); ok {
+			return orderer.ScansOrdered()
+		}
+
+		unwrapper, ok := p.(Unwrapper)
+		if !ok {
+			break
+		}
+
+		p = unwrapper.Unwrap()
+	}
+
+	return false
 }
@@ -0,0 +1,112 @@
+package goukv
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+// plainProvider hides the optional interfaces of the provider it embeds
+type plainProvider struct {
+	Provider
+}
+
+func TestCompareAndSwapThroughWrappers(t *testing.T) {
+	backend := newMemoryProvider()
+	cached := NewCached(Namespace(Wrap(backend, Middleware{}), "ns"), CacheOpts{})
+
+	if !SupportsCompareAndSwap(cached) {
+		t.Fatal("expected the wrappers to support compare-and-swap")
+	}
+
+	if swapped, err := CompareAndSwap(cached, &Entry{Key: []byte("k"), Value: []byte("v1")}, nil); err != nil || !swapped {
+		t.Fatalf("expected the absent key to be swapped, found (%v, %v)", swapped, err)
+	}
+
+	// the value is cached, so the swap must invalidate it
+	cached.Get([]byte("k"))
+
+	if swapped, err := CompareAndSwap(cached, &Entry{Key: []byte("k"), Value: []byte("v2")}, []byte("v1")); err != nil || !swapped {
+		t.Fatalf("expected (v1) to be swapped, found (%v, %v)", swapped, err)
+	}
+
+	if swapped, _ := CompareAndSwap(cached, &Entry{Key: []byte("k"), Value: []byte("v3")}, []byte("v1")); swapped {
+		t.Error("expected the stale old value not to be swapped")
+	}
+
+	if val, err := cached.Get([]byte("k")); err != nil || string(val) != "v2" {
+		t.Errorf("expected (v2), found (%s, %v)", string(val), err)
+	}
+
+	// the key is prefixed by the namespace
+	if _, err := backend.Get([]byte("k")); err != ErrKeyNotFound {
+		t.Errorf("expected (%v), found (%v)", ErrKeyNotFound, err)
+	}
+
+	if val, err := backend.Get(append(namespacePrefix("ns"), 'k')); err != nil || string(val) != "v2" {
+		t.Errorf("expected the namespaced key to hold (v2), found (%s, %v)", string(val), err)
+	}
+
+	unsupported := NewCached(Namespace(Wrap(plainProvider{backend}, Middleware{}), "ns"), CacheOpts{})
+
+	if SupportsCompareAndSwap(unsupported) {
+		t.Error("expected the wrapped provider not to support compare-and-swap")
+	}
+
+	if _, err := CompareAndSwap(unsupported, &Entry{Key: []byte("k"), Value: []byte("v")}, nil); err != ErrCompareAndSwapUnsupported {
+		t.Errorf("expected (%v), found (%v)", ErrCompareAndSwapUnsupported, err)
+	}
+}
+
+// watchingProvider a provider that sends the specified changes to its watchers
+type watchingProvider struct {
+	Provider
+	changes [][]byte
+}
+
+func (w watchingProvider) Watch(ctx context.Context, prefix []byte) (<-chan []byte, error) {
+	keys := make(chan []byte, len(w.changes))
+
+	for _, k := range w.changes {
+		if bytes.HasPrefix(k, prefix) {
+			keys <- k
+		}
+	}
+	close(keys)
+
+	return keys, nil
+}
+
+func TestWatchThroughWrappers(t *testing.T) {
+	backend := watchingProvider{
+		Provider: newMemoryProvider(),
+		changes:  [][]byte{[]byte("q/1"), append(namespacePrefix("ns"), "q/2"...)},
+	}
+
+	watch := func(p Provider) string {
+		changes, err := Watch(context.Background(), p, []byte("q/"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		found := ""
+		for k := range changes {
+			found += string(k) + ","
+		}
+
+		return found
+	}
+
+	if found := watch(NewCached(Wrap(backend, Middleware{}), CacheOpts{})); found != "q/1," {
+		t.Errorf("expected (q/1,), found (%s)", found)
+	}
+
+	// the namespace watches its own keys only, and strips its prefix from them
+	if found := watch(Namespace(Wrap(backend, Middleware{}), "ns")); found != "q/2," {
+		t.Errorf("expected (q/2,), found (%s)", found)
+	}
+
+	if _, err := Watch(context.Background(), Wrap(newMemoryProvider(), Middleware{}), nil); err != ErrWatchUnsupported {
+		t.Errorf("expected (%v), found (%v)", ErrWatchUnsupported, err)
+	}
+}
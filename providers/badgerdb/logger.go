@@ -0,0 +1,39 @@
+package badgerdb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/alash3al/goukv"
+)
+
+// logger adapts a goukv.Logger to badger.Logger
+type logger struct {
+	goukv.Logger
+}
+
+// Errorf implements badger.Logger
+func (l logger) Errorf(format string, args ...interface{}) {
+	l.log(slog.LevelError, format, args...)
+}
+
+// Warningf implements badger.Logger
+func (l logger) Warningf(format string, args ...interface{}) {
+	l.log(slog.LevelWarn, format, args...)
+}
+
+// Infof implements badger.Logger
+func (l logger) Infof(format string, args ...interface{}) {
+	l.log(slog.LevelInfo, format, args...)
+}
+
+// Debugf implements badger.Logger
+func (l logger) Debugf(format string, args ...interface{}) {
+	l.log(slog.LevelDebug, format, args...)
+}
+
+func (l logger) log(level slog.Level, format string, args ...interface{}) {
+	l.Log(context.Background(), level, strings.TrimSpace(fmt.Sprintf(format, args...)), "provider", name)
+}
@@ -1,6 +1,9 @@
 package badgerdb
 
 import (
+	"bytes"
+	"context"
+	"log/slog"
 	"time"
 
 	"github.com/alash3al/goukv"
@@ -20,10 +23,15 @@ func (p Provider) Open(dsn *goukv.DSN) (goukv.Provider, error) {
 
 	syncWrites := dsn.GetBool("sync_writes")
 
+	log, err := goukv.LoggerFromDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
 	badgerOpts := badger.DefaultOptions(path)
 
 	badgerOpts.WithSyncWrites(syncWrites)
-	badgerOpts.WithLogger(nil)
+	badgerOpts = badgerOpts.WithLogger(logger{log})
 	badgerOpts.WithKeepL0InMemory(true)
 	badgerOpts.WithCompression(options.Snappy)
 
@@ -37,12 +45,23 @@ func (p Provider) Open(dsn *goukv.DSN) (goukv.Provider, error) {
 		defer ticker.Stop()
 
 		for range ticker.C {
+			start, rewrites := time.Now(), 0
+
 			for {
 				err := db.RunValueLogGC(0.5)
-				if err != nil {
-					break
+				if err == nil {
+					rewrites++
+					continue
+				}
+
+				if err != badger.ErrNoRewrite && err != badger.ErrRejected {
+					log.Log(context.Background(), slog.LevelWarn, "value log gc failed", "provider", name, "error", err.Error())
 				}
+
+				break
 			}
+
+			log.Log(context.Background(), slog.LevelDebug, "value log gc", "provider", name, "rewrites", rewrites, "duration", time.Since(start))
 		}
 	})()
 
@@ -156,6 +175,16 @@ func (p Provider) Close() error {
 	return p.db.Close()
 }
 
+// Stats implements goukv.Stater
+func (p Provider) Stats() map[string]float64 {
+	lsm, vlog := p.db.Size()
+
+	return map[string]float64{
+		"lsm_size_bytes":  float64(lsm),
+		"vlog_size_bytes": float64(vlog),
+	}
+}
+
 // Scan implements goukv.Scan
 func (p Provider) Scan(opts goukv.ScanOpts) error {
 	if opts.Scanner == nil {
@@ -168,22 +197,38 @@ func (p Provider) Scan(opts goukv.ScanOpts) error {
 	iterOpts := badger.DefaultIteratorOptions
 	iterOpts.Reverse = opts.ReverseScan
 
-	if len(opts.Prefix) > 0 {
+	// a reverse iterator seeks to the greatest key <= the sought one, so a reverse prefix scan seeks to
+	// the first key after the prefix ones then skips it, the iterator prefix can't be used as it would stop there
+	var end []byte
+	if opts.ReverseScan && len(opts.Prefix) > 0 {
+		end = prefixEnd(opts.Prefix)
+	} else if len(opts.Prefix) > 0 {
 		iterOpts.Prefix = opts.Prefix
 	}
 
 	iter := txn.NewIterator(iterOpts)
 	defer iter.Close()
 
-	if opts.Offset != nil {
+	switch {
+	case opts.Offset != nil:
 		iter.Seek(opts.Offset)
-	} else {
+	case end != nil:
+		iter.Seek(end)
+
+		if iter.Valid() && bytes.Equal(iter.Item().Key(), end) {
+			iter.Next()
+		}
+	default:
 		iter.Rewind()
 	}
 
-	for ; iter.Valid(); iter.Next() {
+	for ; iter.ValidForPrefix(opts.Prefix); iter.Next() {
 		item := iter.Item()
 
+		if !opts.IncludeOffset && opts.Offset != nil && bytes.Equal(item.Key(), opts.Offset) {
+			continue
+		}
+
 		key := item.KeyCopy(nil)
 		val, err := item.ValueCopy(nil)
 		if err != nil {
@@ -197,3 +242,23 @@ func (p Provider) Scan(opts goukv.ScanOpts) error {
 
 	return nil
 }
+
+// prefixEnd returns the smallest key greater than all keys having the specified prefix
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+
+	return nil
+}
+
+// ScansOrdered implements goukv.OrderedScanner
+func (p Provider) ScansOrdered() bool {
+	return true
+}
@@ -77,3 +77,81 @@ func TestTTL(t *testing.T) {
 		t.Error(err.Error())
 	}
 }
+
+func TestScanOffset(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		for _, k := range []string{"a", "b1", "b2", "b3", "c"} {
+			db.Put(&goukv.Entry{Key: []byte(k), Value: []byte(k)})
+		}
+
+		cases := []struct {
+			opts     goukv.ScanOpts
+			expected string
+		}{
+			{goukv.ScanOpts{Prefix: []byte("b")}, "b1b2b3"},
+			{goukv.ScanOpts{Prefix: []byte("b"), ReverseScan: true}, "b3b2b1"},
+			{goukv.ScanOpts{Prefix: []byte("b"), Offset: []byte("b2"), IncludeOffset: true}, "b2b3"},
+			{goukv.ScanOpts{Prefix: []byte("b"), Offset: []byte("b2")}, "b3"},
+			{goukv.ScanOpts{Prefix: []byte("b"), Offset: []byte("b2"), ReverseScan: true}, "b1"},
+			{goukv.ScanOpts{Prefix: []byte("b"), Offset: []byte("b25"), ReverseScan: true, IncludeOffset: true}, "b2b1"},
+			{goukv.ScanOpts{Offset: []byte("b15"), IncludeOffset: true}, "b2b3c"},
+			{goukv.ScanOpts{Offset: []byte("z"), ReverseScan: true, IncludeOffset: true}, "cb3b2b1a"},
+		}
+
+		for _, c := range cases {
+			found := ""
+			c.opts.Scanner = func(k, v []byte) bool {
+				found += string(k)
+				return true
+			}
+
+			if err := db.Scan(c.opts); err != nil {
+				t.Error(err)
+			}
+
+			if found != c.expected {
+				t.Errorf("expected (%s), found (%s)", c.expected, found)
+			}
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestReversePrefixScan(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		for _, k := range []string{"a", "b\x01", "b\xff", "b\xff\x01", "c", "\xff\x01", "\xff\xff"} {
+			db.Put(&goukv.Entry{Key: []byte(k), Value: []byte(k)})
+		}
+
+		cases := map[string]string{
+			"b":    "b\xff\x01,b\xff,b\x01,",
+			"\xff": "\xff\xff,\xff\x01,",
+		}
+
+		for prefix, expected := range cases {
+			found := ""
+			err := db.Scan(goukv.ScanOpts{
+				Prefix:      []byte(prefix),
+				ReverseScan: true,
+				Scanner: func(k, v []byte) bool {
+					found += string(k) + ","
+					return true
+				},
+			})
+			if err != nil {
+				t.Error(err)
+			}
+
+			if found != expected {
+				t.Errorf("expected (%q), found (%q)", expected, found)
+			}
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
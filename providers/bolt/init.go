@@ -0,0 +1,11 @@
+package bolt
+
+import "github.com/alash3al/goukv"
+
+const (
+	name = "bolt"
+)
+
+func init() {
+	goukv.Register(name, Provider{})
+}
@@ -0,0 +1,278 @@
+package bolt
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/alash3al/goukv"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	defaultBucket = "goukv"
+)
+
+// Provider represents a driver
+type Provider struct {
+	db     *bolt.DB
+	bucket []byte
+}
+
+// Open implements goukv.Open
+func (p Provider) Open(dsn *goukv.DSN) (goukv.Provider, error) {
+	path := dsn.Hostname() + dsn.Path()
+	bucket := dsn.GetString("bucket")
+
+	if bucket == "" {
+		bucket = defaultBucket
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{
+		Timeout: 5 * time.Second,
+		NoSync:  dsn.GetBool("no_sync"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Provider{
+		db:     db,
+		bucket: []byte(bucket),
+	}, nil
+}
+
+// Put implements goukv.Put
+func (p Provider) Put(e *goukv.Entry) error {
+	return p.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(p.bucket).Put(e.Key, EntryToValue(e).Bytes())
+	})
+}
+
+// Batch perform multi put operation, empty value means *delete*
+func (p Provider) Batch(entries []*goukv.Entry) error {
+	return p.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(p.bucket)
+
+		for _, entry := range entries {
+			var err error
+			if entry.Value == nil {
+				err = b.Delete(entry.Key)
+			} else {
+				err = b.Put(entry.Key, EntryToValue(entry).Bytes())
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// CompareAndSwap implements goukv.CompareAndSwapper
+func (p Provider) CompareAndSwap(e *goukv.Entry, old []byte) (bool, error) {
+	swapped := false
+
+	err := p.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(p.bucket)
+
+		current := b.Get(e.Key)
+		if current != nil && BytesToValue(current).IsExpired() {
+			current = nil
+		}
+
+		if (old == nil) != (current == nil) || (old != nil && !bytes.Equal(BytesToValue(current).Value, old)) {
+			return nil
+		}
+
+		swapped = true
+
+		if e.Value == nil {
+			return b.Delete(e.Key)
+		}
+
+		return b.Put(e.Key, EntryToValue(e).Bytes())
+	})
+
+	return swapped, err
+}
+
+// Get implements goukv.Get
+func (p Provider) Get(k []byte) ([]byte, error) {
+	var val Value
+
+	err := p.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(p.bucket).Get(k)
+		if b == nil {
+			return goukv.ErrKeyNotFound
+		}
+
+		val = BytesToValue(b)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if val.IsExpired() {
+		return nil, goukv.ErrKeyExpired
+	}
+
+	return val.Value, nil
+}
+
+// TTL implements goukv.TTL
+func (p Provider) TTL(k []byte) (*time.Time, error) {
+	var val Value
+
+	err := p.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(p.bucket).Get(k)
+		if b == nil {
+			return goukv.ErrKeyNotFound
+		}
+
+		val = BytesToValue(b)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return val.Expires, nil
+}
+
+// Delete implements goukv.Delete
+func (p Provider) Delete(k []byte) error {
+	return p.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(p.bucket).Delete(k)
+	})
+}
+
+// Close implements goukv.Close
+func (p Provider) Close() error {
+	return p.db.Close()
+}
+
+// Scan implements goukv.Scan
+func (p Provider) Scan(opts goukv.ScanOpts) error {
+	if opts.Scanner == nil {
+		return nil
+	}
+
+	return p.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(p.bucket).Cursor()
+
+		var k, v []byte
+		var next func() ([]byte, []byte)
+
+		if opts.ReverseScan {
+			next = cursor.Prev
+			k, v = seekReverse(cursor, opts)
+		} else {
+			next = cursor.Next
+			k, v = seekForward(cursor, opts)
+		}
+
+		for ; k != nil; k, v = next() {
+			if len(opts.Prefix) > 0 && !bytes.HasPrefix(k, opts.Prefix) {
+				break
+			}
+
+			if !opts.IncludeOffset && opts.Offset != nil && bytes.Equal(k, opts.Offset) {
+				continue
+			}
+
+			decodedValue := BytesToValue(v)
+			if decodedValue.IsExpired() {
+				continue
+			}
+
+			newK := make([]byte, len(k))
+			copy(newK, k)
+
+			if !opts.Scanner(newK, decodedValue.Value) {
+				break
+			}
+		}
+
+		return nil
+	})
+}
+
+// seekForward positions the cursor at the first key of an ascending scan
+func seekForward(cursor *bolt.Cursor, opts goukv.ScanOpts) ([]byte, []byte) {
+	if opts.Offset != nil {
+		return cursor.Seek(opts.Offset)
+	}
+
+	if len(opts.Prefix) > 0 {
+		return cursor.Seek(opts.Prefix)
+	}
+
+	return cursor.First()
+}
+
+// seekReverse positions the cursor at the first key of a descending scan
+func seekReverse(cursor *bolt.Cursor, opts goukv.ScanOpts) ([]byte, []byte) {
+	var upper []byte
+
+	if opts.Offset != nil {
+		k, v := cursor.Seek(opts.Offset)
+		if k == nil {
+			return cursor.Last()
+		}
+
+		if bytes.Equal(k, opts.Offset) {
+			return k, v
+		}
+
+		return cursor.Prev()
+	}
+
+	if len(opts.Prefix) > 0 {
+		upper = prefixEnd(opts.Prefix)
+	}
+
+	if upper == nil {
+		return cursor.Last()
+	}
+
+	if k, _ := cursor.Seek(upper); k == nil {
+		return cursor.Last()
+	}
+
+	return cursor.Prev()
+}
+
+// prefixEnd returns the smallest key greater than all keys having the specified prefix
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+
+	return nil
+}
+
+// ScansOrdered implements goukv.OrderedScanner
+func (p Provider) ScansOrdered() bool {
+	return true
+}
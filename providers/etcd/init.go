@@ -0,0 +1,11 @@
+package etcd
+
+import "github.com/alash3al/goukv"
+
+const (
+	name = "etcd"
+)
+
+func init() {
+	goukv.Register(name, Provider{})
+}
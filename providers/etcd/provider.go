@@ -0,0 +1,335 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/alash3al/goukv"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	defaultPort     = "2379"
+	defaultTimeout  = 5
+	defaultPageSize = 1000
+)
+
+// Provider represents a driver
+type Provider struct {
+	client         *clientv3.Client
+	requestTimeout time.Duration
+	pageSize       int64
+}
+
+// Open implements goukv.Open
+func (p Provider) Open(dsn *goukv.DSN) (goukv.Provider, error) {
+	port := dsn.Port()
+	if port == "" {
+		port = defaultPort
+	}
+
+	endpoints := []string{net.JoinHostPort(dsn.Hostname(), port)}
+	if extra := dsn.GetString("endpoints"); extra != "" {
+		endpoints = append(endpoints, strings.Split(extra, ",")...)
+	}
+
+	dialTimeout := dsn.GetInt("dial_timeout")
+	if dialTimeout < 1 {
+		dialTimeout = defaultTimeout
+	}
+
+	requestTimeout := dsn.GetInt("request_timeout")
+	if requestTimeout < 1 {
+		requestTimeout = defaultTimeout
+	}
+
+	pageSize := dsn.GetInt64("page_size")
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		Username:    dsn.Username(),
+		Password:    dsn.Password(),
+		DialTimeout: time.Duration(dialTimeout) * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Provider{
+		client:         client,
+		requestTimeout: time.Duration(requestTimeout) * time.Second,
+		pageSize:       pageSize,
+	}, nil
+}
+
+// Put implements goukv.Put
+func (p Provider) Put(e *goukv.Entry) error {
+	ctx, cancel := p.context()
+	defer cancel()
+
+	op, err := p.putOp(ctx, e)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.client.Do(ctx, op)
+
+	return err
+}
+
+// Batch perform multi put operation, empty value means *delete*
+// the entries are applied using a single transaction which rejects duplicated keys,
+// so only the last entry of a duplicated key is applied, the same as if they were applied in order.
+func (p Provider) Batch(entries []*goukv.Entry) error {
+	ctx, cancel := p.context()
+	defer cancel()
+
+	ops := []clientv3.Op{}
+
+	last := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		last[string(entry.Key)] = i
+	}
+
+	for i, entry := range entries {
+		if last[string(entry.Key)] != i {
+			continue
+		}
+
+		if entry.Value == nil {
+			ops = append(ops, clientv3.OpDelete(string(entry.Key)))
+			continue
+		}
+
+		op, err := p.putOp(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		ops = append(ops, op)
+	}
+
+	_, err := p.client.Txn(ctx).Then(ops...).Commit()
+
+	return err
+}
+
+// Get implements goukv.Get
+func (p Provider) Get(k []byte) ([]byte, error) {
+	ctx, cancel := p.context()
+	defer cancel()
+
+	resp, err := p.client.Get(ctx, string(k))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Kvs) < 1 {
+		return nil, goukv.ErrKeyNotFound
+	}
+
+	return resp.Kvs[0].Value, nil
+}
+
+// TTL implements goukv.TTL
+func (p Provider) TTL(k []byte) (*time.Time, error) {
+	ctx, cancel := p.context()
+	defer cancel()
+
+	resp, err := p.client.Get(ctx, string(k))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Kvs) < 1 {
+		return nil, goukv.ErrKeyNotFound
+	}
+
+	if resp.Kvs[0].Lease == 0 {
+		return nil, nil
+	}
+
+	lease, err := p.client.TimeToLive(ctx, clientv3.LeaseID(resp.Kvs[0].Lease))
+	if err != nil {
+		return nil, err
+	}
+
+	// the lease has been revoked/expired in the meantime
+	if lease.TTL < 0 {
+		return nil, goukv.ErrKeyExpired
+	}
+
+	expiresAt := time.Now().Add(time.Duration(lease.TTL) * time.Second)
+
+	return &expiresAt, nil
+}
+
+// Delete implements goukv.Delete
+func (p Provider) Delete(k []byte) error {
+	ctx, cancel := p.context()
+	defer cancel()
+
+	_, err := p.client.Delete(ctx, string(k))
+
+	return err
+}
+
+// CompareAndSwap implements goukv.CompareAndSwapper using a transaction comparing the current value,
+// or the creation revision of the key if it is expected to be absent
+func (p Provider) CompareAndSwap(e *goukv.Entry, old []byte) (bool, error) {
+	ctx, cancel := p.context()
+	defer cancel()
+
+	key := string(e.Key)
+
+	cmp := clientv3.Compare(clientv3.Value(key), "=", string(old))
+	if old == nil {
+		cmp = clientv3.Compare(clientv3.CreateRevision(key), "=", 0)
+	}
+
+	op := clientv3.OpDelete(key)
+	if e.Value != nil {
+		var err error
+
+		if op, err = p.putOp(ctx, e); err != nil {
+			return false, err
+		}
+	}
+
+	resp, err := p.client.Txn(ctx).If(cmp).Then(op).Commit()
+	if err != nil {
+		return false, err
+	}
+
+	return resp.Succeeded, nil
+}
+
+// Close implements goukv.Close
+func (p Provider) Close() error {
+	return p.client.Close()
+}
+
+// Watch implements goukv.Watcher
+func (p Provider) Watch(ctx context.Context, prefix []byte) (<-chan []byte, error) {
+	keys := make(chan []byte)
+	changes := p.client.Watch(clientv3.WithRequireLeader(ctx), string(prefix), clientv3.WithPrefix())
+
+	go func() {
+		defer close(keys)
+
+		for resp := range changes {
+			if resp.Err() != nil {
+				return
+			}
+
+			for _, event := range resp.Events {
+				select {
+				case keys <- event.Kv.Key:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return keys, nil
+}
+
+// Scan implements goukv.Scan
+func (p Provider) Scan(opts goukv.ScanOpts) error {
+	if opts.Scanner == nil {
+		return nil
+	}
+
+	// "\x00" as a range end means "till the last key"
+	start, end := []byte{0}, []byte{0}
+	if len(opts.Prefix) > 0 {
+		start = opts.Prefix
+		end = []byte(clientv3.GetPrefixRangeEnd(string(opts.Prefix)))
+	}
+
+	order := clientv3.SortAscend
+	if opts.ReverseScan {
+		order = clientv3.SortDescend
+	}
+
+	if opts.Offset != nil {
+		if opts.ReverseScan {
+			offsetEnd := append(append([]byte{}, opts.Offset...), 0)
+			if bytes.Equal(end, []byte{0}) || bytes.Compare(offsetEnd, end) < 0 {
+				end = offsetEnd
+			}
+		} else if bytes.Compare(opts.Offset, start) > 0 {
+			start = opts.Offset
+		}
+	}
+
+	for {
+		ctx, cancel := p.context()
+		resp, err := p.client.Get(
+			ctx,
+			string(start),
+			clientv3.WithRange(string(end)),
+			clientv3.WithSort(clientv3.SortByKey, order),
+			clientv3.WithLimit(p.pageSize),
+		)
+		cancel()
+
+		if err != nil {
+			return err
+		}
+
+		for _, kv := range resp.Kvs {
+			if !opts.IncludeOffset && opts.Offset != nil && bytes.Equal(kv.Key, opts.Offset) {
+				continue
+			}
+
+			if !opts.Scanner(kv.Key, kv.Value) {
+				return nil
+			}
+		}
+
+		if !resp.More || len(resp.Kvs) < 1 {
+			return nil
+		}
+
+		last := resp.Kvs[len(resp.Kvs)-1].Key
+		if opts.ReverseScan {
+			end = last
+		} else {
+			start = append(append([]byte{}, last...), 0)
+		}
+	}
+}
+
+// putOp builds a put operation for the specified entry, granting a lease if it has a TTL
+func (p Provider) putOp(ctx context.Context, e *goukv.Entry) (clientv3.Op, error) {
+	if e.TTL <= 0 {
+		return clientv3.OpPut(string(e.Key), string(e.Value)), nil
+	}
+
+	seconds := int64((e.TTL + time.Second - 1) / time.Second)
+
+	lease, err := p.client.Grant(ctx, seconds)
+	if err != nil {
+		return clientv3.Op{}, err
+	}
+
+	return clientv3.OpPut(string(e.Key), string(e.Value), clientv3.WithLease(lease.ID)), nil
+}
+
+// context returns a context bounded by the request timeout
+func (p Provider) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), p.requestTimeout)
+}
+
+// ScansOrdered implements goukv.OrderedScanner
+func (p Provider) ScansOrdered() bool {
+	return true
+}
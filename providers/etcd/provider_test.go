@@ -0,0 +1,216 @@
+package etcd
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alash3al/goukv"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+func openDBAndDo(fn func(db goukv.Provider)) error {
+	dir, err := os.MkdirTemp("", "goukv-etcd")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	clientURL, _ := url.Parse("http://127.0.0.1:23790")
+	peerURL, _ := url.Parse("http://127.0.0.1:23800")
+
+	cfg := embed.NewConfig()
+	cfg.Dir = dir
+	cfg.LogLevel = "error"
+	cfg.ListenClientUrls, cfg.AdvertiseClientUrls = []url.URL{*clientURL}, []url.URL{*clientURL}
+	cfg.ListenPeerUrls, cfg.AdvertisePeerUrls = []url.URL{*peerURL}, []url.URL{*peerURL}
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+
+	server, err := embed.StartEtcd(cfg)
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+
+	select {
+	case <-server.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		return errors.New("timeout while waiting for the embedded etcd server")
+	}
+
+	p := Provider{}
+	dsn, err := goukv.NewDSN("etcd://127.0.0.1:23790/?page_size=2")
+	if err != nil {
+		return err
+	}
+	db, err := p.Open(dsn)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		db.Close()
+	}()
+
+	fn(db)
+
+	return nil
+}
+
+func TestPutGet(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		entry := goukv.Entry{
+			Key:   []byte("k"),
+			Value: []byte("v"),
+		}
+		err := db.Put(&entry)
+		if err != nil {
+			t.Error(err)
+		}
+		val, err := db.Get(entry.Key)
+		if err != nil {
+			t.Error(err)
+		}
+		if string(val) != string(entry.Value) {
+			t.Errorf("expected (%s), found(%s)", string(entry.Value), string(val))
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestTTL(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		entry := goukv.Entry{
+			Key:   []byte("k"),
+			Value: []byte("v"),
+			TTL:   time.Second * 10,
+		}
+		err := db.Put(&entry)
+		if err != nil {
+			t.Error(err)
+		}
+		expiresAt, err := db.TTL(entry.Key)
+		if err != nil {
+			t.Error(err)
+		}
+		if !(expiresAt.Before(time.Now().Add(entry.TTL)) || expiresAt.Equal(time.Now().Add(entry.TTL))) {
+			t.Errorf("expected to be expires <= (%d), found (%d)", time.Now().Add(entry.TTL).Unix(), expiresAt.Unix())
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestBatchScan(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		err := db.Batch([]*goukv.Entry{
+			{Key: []byte("a/1"), Value: []byte("1")},
+			{Key: []byte("a/3"), Value: []byte("3")},
+			{Key: []byte("a/2"), Value: []byte("2")},
+			{Key: []byte("b/1"), Value: []byte("1")},
+		})
+		if err != nil {
+			t.Error(err)
+		}
+
+		scan := func(opts goukv.ScanOpts) string {
+			found := ""
+			opts.Scanner = func(k, v []byte) bool {
+				found += string(k) + ","
+				return true
+			}
+			if err := db.Scan(opts); err != nil {
+				t.Error(err)
+			}
+			return found
+		}
+
+		cases := map[string]goukv.ScanOpts{
+			"a/1,a/2,a/3,":     {Prefix: []byte("a/")},
+			"a/3,a/2,a/1,":     {Prefix: []byte("a/"), ReverseScan: true},
+			"a/3,b/1,":         {Offset: []byte("a/2")},
+			"a/2,a/3,b/1,":     {Offset: []byte("a/2"), IncludeOffset: true},
+			"a/1,":             {Offset: []byte("a/2"), ReverseScan: true},
+			"b/1,a/3,a/2,a/1,": {ReverseScan: true},
+		}
+
+		for expected, opts := range cases {
+			if found := scan(opts); found != expected {
+				t.Errorf("expected (%s), found (%s)", expected, found)
+			}
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestBatchDuplicatedKeys(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		err := db.Batch([]*goukv.Entry{
+			{Key: []byte("k1"), Value: []byte("v1")},
+			{Key: []byte("k2"), Value: []byte("v2")},
+			{Key: []byte("k1"), Value: []byte("v3")},
+			{Key: []byte("k2"), Value: nil},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if val, err := db.Get([]byte("k1")); err != nil || string(val) != "v3" {
+			t.Errorf("expected (v3), found (%s, %v)", val, err)
+		}
+
+		if _, err := db.Get([]byte("k2")); err != goukv.ErrKeyNotFound {
+			t.Errorf("expected (%v), found (%v)", goukv.ErrKeyNotFound, err)
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		cas := db.(goukv.CompareAndSwapper)
+		key := []byte("cas")
+		db.Delete(key)
+
+		steps := []struct {
+			value, old []byte
+			expected   bool
+		}{
+			{[]byte("a"), nil, true},
+			{[]byte("b"), nil, false},
+			{[]byte("b"), []byte("x"), false},
+			{[]byte("b"), []byte("a"), true},
+			{nil, []byte("a"), false},
+			{nil, []byte("b"), true},
+			{nil, nil, true},
+		}
+
+		for i, step := range steps {
+			swapped, err := cas.CompareAndSwap(&goukv.Entry{Key: key, Value: step.value}, step.old)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if swapped != step.expected {
+				t.Errorf("step (%d): expected (%v), found (%v)", i, step.expected, swapped)
+			}
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
@@ -0,0 +1,134 @@
+package fs
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alash3al/goukv"
+)
+
+const (
+	fileExt = ".kv"
+)
+
+// File represents the contents of a key file, it is stored as a header line
+// holding the expiration unix-nano timestamp (0 means never) followed by the raw value
+type File struct {
+	Value   []byte
+	Expires *time.Time
+}
+
+// Bytes encodes the file to a byte array
+func (f File) Bytes() []byte {
+	var expires int64
+	if f.Expires != nil {
+		expires = f.Expires.UnixNano()
+	}
+
+	header := strconv.FormatInt(expires, 10) + "\n"
+
+	return append([]byte(header), f.Value...)
+}
+
+// IsExpired whether the file is expired or not
+func (f File) IsExpired() bool {
+	if f.Expires == nil {
+		return false
+	}
+
+	expires := *(f.Expires)
+	return time.Now().After(expires) || time.Now().Equal(expires)
+}
+
+// EntryToFile build a file from entry representation
+func EntryToFile(e *goukv.Entry) File {
+	f := File{
+		Value:   e.Value,
+		Expires: nil,
+	}
+
+	if e.TTL > 0 {
+		expires := time.Now().Add(e.TTL)
+		f.Expires = &expires
+	}
+
+	return f
+}
+
+// BytesToFile decodes the specified byte array to File
+func BytesToFile(b []byte) (f File, err error) {
+	i := bytes.IndexByte(b, '\n')
+	if i < 0 {
+		return f, errors.New("invalid file header")
+	}
+
+	expires, err := strconv.ParseInt(string(b[:i]), 10, 64)
+	if err != nil {
+		return f, err
+	}
+
+	if expires > 0 {
+		t := time.Unix(0, expires)
+		f.Expires = &t
+	}
+
+	f.Value = b[i+1:]
+
+	return f, nil
+}
+
+// encodeKey encodes the specified key to a safe file name, lowercase letters, digits, '-' and '_'
+// are kept as is for readability, any other byte is escaped as %XX so that names never collide
+// on case-insensitive file systems
+func encodeKey(k []byte) string {
+	var buf strings.Builder
+
+	for _, c := range k {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' {
+			buf.WriteByte(c)
+			continue
+		}
+
+		buf.WriteByte('%')
+		buf.WriteString(hex.EncodeToString([]byte{c}))
+	}
+
+	buf.WriteString(fileExt)
+
+	return buf.String()
+}
+
+// decodeKey decodes the specified file name back to its key
+func decodeKey(name string) ([]byte, bool) {
+	if !strings.HasSuffix(name, fileExt) {
+		return nil, false
+	}
+
+	name = strings.TrimSuffix(name, fileExt)
+	k := make([]byte, 0, len(name))
+
+	for i := 0; i < len(name); i++ {
+		if name[i] != '%' {
+			k = append(k, name[i])
+			continue
+		}
+
+		if i+2 >= len(name) {
+			return nil, false
+		}
+
+		b, err := hex.DecodeString(name[i+1 : i+3])
+		if err != nil {
+			return nil, false
+		}
+
+		k = append(k, b...)
+		i += 2
+	}
+
+	return k, true
+}
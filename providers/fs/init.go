@@ -0,0 +1,11 @@
+package fs
+
+import "github.com/alash3al/goukv"
+
+const (
+	name = "fs"
+)
+
+func init() {
+	goukv.Register(name, Provider{})
+}
@@ -0,0 +1,242 @@
+package fs
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/alash3al/goukv"
+)
+
+const (
+	dataDir     = "data"
+	tmpDir      = "tmp"
+	maxNameSize = 255
+)
+
+// error related variables
+var (
+	ErrKeyTooLong = errors.New("the specified key is too long to be used as a file name")
+)
+
+// Provider represents a driver
+type Provider struct {
+	data       string
+	tmp        string
+	syncWrites bool
+}
+
+// Open implements goukv.Open
+func (p Provider) Open(dsn *goukv.DSN) (goukv.Provider, error) {
+	path := dsn.Hostname() + dsn.Path()
+
+	data := filepath.Join(path, dataDir)
+	tmp := filepath.Join(path, tmpDir)
+
+	for _, dir := range []string{data, tmp} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Provider{
+		data:       data,
+		tmp:        tmp,
+		syncWrites: dsn.GetBool("sync_writes"),
+	}, nil
+}
+
+// Put implements goukv.Put
+func (p Provider) Put(e *goukv.Entry) error {
+	filename, err := p.filename(e.Key)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(p.tmp, "put-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(EntryToFile(e).Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if p.syncWrites {
+		if err := tmp.Sync(); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
+
+// Batch perform multi put operation, empty value means *delete*
+// each entry is written atomically, but the batch as a whole isn't.
+func (p Provider) Batch(entries []*goukv.Entry) error {
+	for _, entry := range entries {
+		var err error
+		if entry.Value == nil {
+			err = p.Delete(entry.Key)
+		} else {
+			err = p.Put(entry)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get implements goukv.Get
+func (p Provider) Get(k []byte) ([]byte, error) {
+	f, err := p.read(k)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.IsExpired() {
+		return nil, goukv.ErrKeyExpired
+	}
+
+	return f.Value, nil
+}
+
+// TTL implements goukv.TTL
+func (p Provider) TTL(k []byte) (*time.Time, error) {
+	f, err := p.read(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.Expires, nil
+}
+
+// Delete implements goukv.Delete
+func (p Provider) Delete(k []byte) error {
+	filename, err := p.filename(k)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Close implements goukv.Close
+func (p Provider) Close() error {
+	return nil
+}
+
+// Scan implements goukv.Scan
+func (p Provider) Scan(opts goukv.ScanOpts) error {
+	if opts.Scanner == nil {
+		return nil
+	}
+
+	infos, err := ioutil.ReadDir(p.data)
+	if err != nil {
+		return err
+	}
+
+	// the file names are sorted, but the escaping doesn't preserve the keys order
+	keys := [][]byte{}
+	for _, info := range infos {
+		k, ok := decodeKey(info.Name())
+		if !ok || !bytes.HasPrefix(k, opts.Prefix) {
+			continue
+		}
+
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if opts.ReverseScan {
+			return bytes.Compare(keys[i], keys[j]) > 0
+		}
+
+		return bytes.Compare(keys[i], keys[j]) < 0
+	})
+
+	for _, k := range keys {
+		if opts.Offset != nil {
+			cmp := bytes.Compare(k, opts.Offset)
+			if opts.ReverseScan {
+				cmp = -cmp
+			}
+
+			if cmp < 0 || (cmp == 0 && !opts.IncludeOffset) {
+				continue
+			}
+		}
+
+		f, err := p.read(k)
+		if err == goukv.ErrKeyNotFound {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if f.IsExpired() {
+			continue
+		}
+
+		if !opts.Scanner(k, f.Value) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// read loads and decodes the file of the specified key
+func (p Provider) read(k []byte) (File, error) {
+	filename, err := p.filename(k)
+	if err != nil {
+		return File{}, err
+	}
+
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return File{}, goukv.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return File{}, err
+	}
+
+	return BytesToFile(b)
+}
+
+// filename returns the path of the file that holds the specified key
+func (p Provider) filename(k []byte) (string, error) {
+	name := encodeKey(k)
+	if len(name) > maxNameSize {
+		return "", ErrKeyTooLong
+	}
+
+	return filepath.Join(p.data, name), nil
+}
+
+// ScansOrdered implements goukv.OrderedScanner
+func (p Provider) ScansOrdered() bool {
+	return true
+}
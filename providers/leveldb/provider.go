@@ -1,6 +1,11 @@
 package leveldb
 
 import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/alash3al/goukv"
@@ -15,6 +20,7 @@ import (
 type Provider struct {
 	db         *leveldb.DB
 	syncWrites bool
+	casLock    *sync.Mutex
 }
 
 // Open implements goukv.Open
@@ -37,6 +43,7 @@ func (p Provider) Open(dsn *goukv.DSN) (goukv.Provider, error) {
 	return &Provider{
 		db:         db,
 		syncWrites: syncWrites,
+		casLock:    &sync.Mutex{},
 	}, nil
 }
 
@@ -64,6 +71,36 @@ func (p Provider) Batch(entries []*goukv.Entry) error {
 	})
 }
 
+// CompareAndSwap implements goukv.CompareAndSwapper, leveldb can't be opened by more than one process,
+// so the conditional writes are serialized by a lock, though they aren't atomic relative to the other writes
+func (p Provider) CompareAndSwap(e *goukv.Entry, old []byte) (bool, error) {
+	p.casLock.Lock()
+	defer p.casLock.Unlock()
+
+	current, err := p.db.Get(e.Key, nil)
+	if err == leveldb.ErrNotFound {
+		current, err = nil, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if current != nil && BytesToValue(current).IsExpired() {
+		current = nil
+	}
+
+	if (old == nil) != (current == nil) || (old != nil && !bytes.Equal(BytesToValue(current).Value, old)) {
+		return false, nil
+	}
+
+	if e.Value == nil {
+		return true, p.Delete(e.Key)
+	}
+
+	return true, p.Put(e)
+}
+
 // Get implements goukv.Get
 func (p Provider) Get(k []byte) ([]byte, error) {
 	b, err := p.db.Get(k, nil)
@@ -108,6 +145,32 @@ func (p Provider) Close() error {
 	return p.db.Close()
 }
 
+// Stats implements goukv.Stater
+func (p Provider) Stats() map[string]float64 {
+	stats := map[string]float64{}
+	properties := []string{"cachedblock", "openedtables", "alivesnaps", "aliveiters"}
+
+	for level := 0; level < 7; level++ {
+		properties = append(properties, fmt.Sprintf("num-files-at-level%d", level))
+	}
+
+	for _, property := range properties {
+		val, err := p.db.GetProperty("leveldb." + property)
+		if err != nil {
+			continue
+		}
+
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			continue
+		}
+
+		stats[strings.Replace(property, "-", "_", -1)] = f
+	}
+
+	return stats
+}
+
 // Scan implements goukv.Scan
 func (p Provider) Scan(opts goukv.ScanOpts) error {
 	if opts.Scanner == nil {
@@ -129,20 +192,32 @@ func (p Provider) Scan(opts goukv.ScanOpts) error {
 		next = iter.Next
 	}
 
-	if opts.Offset != nil {
-		iter.Seek(opts.Offset)
-	}
+	// position the iterator at the first key to be scanned
+	var valid bool
 
-	if opts.ReverseScan && opts.Offset == nil && opts.Prefix == nil {
-		iter.Last()
+	switch {
+	case opts.Offset != nil && opts.ReverseScan:
+		if !iter.Seek(opts.Offset) {
+			valid = iter.Last()
+		} else if !bytes.Equal(iter.Key(), opts.Offset) {
+			valid = iter.Prev()
+		} else {
+			valid = true
+		}
+	case opts.Offset != nil:
+		valid = iter.Seek(opts.Offset)
+	case opts.ReverseScan:
+		valid = iter.Last()
+	default:
+		valid = iter.First()
 	}
 
-	if opts.Offset != nil && !opts.IncludeOffset {
-		next()
+	if valid && opts.Offset != nil && !opts.IncludeOffset && bytes.Equal(iter.Key(), opts.Offset) {
+		valid = next()
 	}
 
 	defer iter.Release()
-	for next() {
+	for ; valid; valid = next() {
 		if err := iter.Error(); err != nil {
 			break
 		}
@@ -175,3 +250,8 @@ func (p Provider) Scan(opts goukv.ScanOpts) error {
 
 	return nil
 }
+
+// ScansOrdered implements goukv.OrderedScanner
+func (p Provider) ScansOrdered() bool {
+	return true
+}
@@ -78,3 +78,81 @@ func TestTTL(t *testing.T) {
 		t.Error(err.Error())
 	}
 }
+
+func TestScanOffset(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		for _, k := range []string{"a", "b1", "b2", "b3", "c"} {
+			db.Put(&goukv.Entry{Key: []byte(k), Value: []byte(k)})
+		}
+
+		cases := []struct {
+			opts     goukv.ScanOpts
+			expected string
+		}{
+			{goukv.ScanOpts{Prefix: []byte("b")}, "b1b2b3"},
+			{goukv.ScanOpts{Prefix: []byte("b"), ReverseScan: true}, "b3b2b1"},
+			{goukv.ScanOpts{Prefix: []byte("b"), Offset: []byte("b2"), IncludeOffset: true}, "b2b3"},
+			{goukv.ScanOpts{Prefix: []byte("b"), Offset: []byte("b2")}, "b3"},
+			{goukv.ScanOpts{Prefix: []byte("b"), Offset: []byte("b2"), ReverseScan: true}, "b1"},
+			{goukv.ScanOpts{Prefix: []byte("b"), Offset: []byte("b25"), ReverseScan: true, IncludeOffset: true}, "b2b1"},
+			{goukv.ScanOpts{Offset: []byte("b15"), IncludeOffset: true}, "b2b3c"},
+			{goukv.ScanOpts{Offset: []byte("z"), ReverseScan: true, IncludeOffset: true}, "cb3b2b1a"},
+		}
+
+		for _, c := range cases {
+			found := ""
+			c.opts.Scanner = func(k, v []byte) bool {
+				found += string(k)
+				return true
+			}
+
+			if err := db.Scan(c.opts); err != nil {
+				t.Error(err)
+			}
+
+			if found != c.expected {
+				t.Errorf("expected (%s), found (%s)", c.expected, found)
+			}
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		cas := db.(goukv.CompareAndSwapper)
+		key := []byte("cas")
+		db.Delete(key)
+
+		steps := []struct {
+			value, old []byte
+			expected   bool
+		}{
+			{[]byte("a"), nil, true},
+			{[]byte("b"), nil, false},
+			{[]byte("b"), []byte("x"), false},
+			{[]byte("b"), []byte("a"), true},
+			{nil, []byte("a"), false},
+			{nil, []byte("b"), true},
+			{nil, nil, true},
+		}
+
+		for i, step := range steps {
+			swapped, err := cas.CompareAndSwap(&goukv.Entry{Key: key, Value: step.value}, step.old)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if swapped != step.expected {
+				t.Errorf("step (%d): expected (%v), found (%v)", i, step.expected, swapped)
+			}
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
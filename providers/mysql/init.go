@@ -0,0 +1,11 @@
+package mysql
+
+import "github.com/alash3al/goukv"
+
+const (
+	name = "mysql"
+)
+
+func init() {
+	goukv.Register(name, Provider{})
+}
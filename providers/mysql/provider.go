@@ -0,0 +1,256 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alash3al/goukv"
+	"github.com/jmoiron/sqlx"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+const (
+	defaultTable = "goukv"
+	defaultPort  = "3306"
+)
+
+// Provider represents a driver
+type Provider struct {
+	db    *sqlx.DB
+	table string
+}
+
+// Open implements goukv.Open
+func (p Provider) Open(dsn *goukv.DSN) (goukv.Provider, error) {
+	port := dsn.Port()
+	if port == "" {
+		port = defaultPort
+	}
+
+	driverDSN := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", dsn.Username(), dsn.Password(), dsn.Hostname(), port, strings.TrimPrefix(dsn.Path(), "/"))
+	db, err := sqlx.Connect("mysql", driverDSN)
+	if err != nil {
+		return nil, err
+	}
+
+	table := dsn.GetString("table")
+	if table == "" {
+		table = defaultTable
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS ` + (table) + ` (
+			_k VARBINARY(3072) NOT NULL PRIMARY KEY,
+			_v LONGBLOB,
+			_x BIGINT DEFAULT 0
+		)
+	`); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Provider{
+		db:    db,
+		table: table,
+	}, nil
+}
+
+// Put implements goukv.Put
+func (p Provider) Put(e *goukv.Entry) error {
+	return p.put(p.db, e)
+}
+
+// Get implements goukv.Get
+func (p Provider) Get(k []byte) ([]byte, error) {
+	var item Item
+
+	err := p.db.Get(&item, `SELECT * FROM `+(p.table)+` WHERE _k = ?`, k)
+	if err == sql.ErrNoRows {
+		return nil, goukv.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if item.Expired() {
+		return nil, goukv.ErrKeyExpired
+	}
+
+	return item.V, nil
+}
+
+// TTL implements goukv.TTL
+func (p Provider) TTL(k []byte) (*time.Time, error) {
+	var item Item
+
+	err := p.db.Get(&item, `SELECT * FROM `+(p.table)+` WHERE _k = ?`, k)
+	if err == sql.ErrNoRows {
+		return nil, goukv.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if item.X > 0 {
+		expiresAt := item.ExpiresAt()
+		return &expiresAt, nil
+	}
+
+	return nil, nil
+}
+
+// Delete implements goukv.Delete
+func (p Provider) Delete(k []byte) error {
+	return p.delete(p.db, k)
+}
+
+// Batch perform multi put operation, empty value means *delete*
+func (p Provider) Batch(entries []*goukv.Entry) error {
+	tx, err := p.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.Value == nil {
+			err = p.delete(tx, entry.Key)
+		} else {
+			err = p.put(tx, entry)
+		}
+
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Close implements goukv.Close
+func (p Provider) Close() error {
+	return p.db.Close()
+}
+
+// Scan implements goukv.Scan
+func (p Provider) Scan(opts goukv.ScanOpts) error {
+	if opts.Scanner == nil {
+		return nil
+	}
+
+	query := `SELECT * FROM ` + (p.table) + ``
+	where := []string{}
+	sortOrder := "ASC"
+	args := []interface{}{}
+
+	if opts.ReverseScan {
+		sortOrder = "DESC"
+	}
+
+	if len(opts.Offset) > 0 {
+		op := ">"
+		if opts.ReverseScan {
+			op = "<"
+		}
+
+		if opts.IncludeOffset {
+			op += "="
+		}
+
+		where = append(where, `_k `+op+` ?`)
+		args = append(args, opts.Offset)
+	}
+
+	if len(opts.Prefix) > 0 {
+		where = append(where, `_k >= ?`)
+		args = append(args, opts.Prefix)
+
+		if end := prefixEnd(opts.Prefix); end != nil {
+			where = append(where, `_k < ?`)
+			args = append(args, end)
+		}
+	}
+
+	if len(where) > 0 {
+		query += " WHERE (" + strings.Join(where, ") AND (") + ")"
+	}
+
+	query += " ORDER BY _k " + sortOrder
+
+	rows, err := p.db.Queryx(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item Item
+
+		if err := rows.StructScan(&item); err != nil {
+			return err
+		}
+
+		if item.Expired() {
+			continue
+		}
+
+		if !opts.Scanner(item.K, item.V) {
+			break
+		}
+	}
+
+	return rows.Err()
+}
+
+// put inserts or replaces the specified entry using the specified executor
+func (p Provider) put(exec sqlx.Execer, e *goukv.Entry) error {
+	item := Item{
+		K: e.Key,
+		V: e.Value,
+		X: 0,
+	}
+
+	if e.TTL > 0 {
+		item.X = time.Now().Add(e.TTL).Unix()
+	}
+
+	_, err := exec.Exec(`
+		INSERT INTO `+(p.table)+`(_k, _v, _x) VALUES(?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			_v = VALUES(_v),
+			_x = VALUES(_x)
+	`, item.K, item.V, item.X)
+
+	return err
+}
+
+// delete removes the specified key using the specified executor
+func (p Provider) delete(exec sqlx.Execer, k []byte) error {
+	_, err := exec.Exec(`DELETE FROM `+(p.table)+` WHERE _k = ?`, k)
+	return err
+}
+
+// prefixEnd returns the smallest key greater than all keys having the specified prefix
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+
+	return nil
+}
+
+// ScansOrdered implements goukv.OrderedScanner
+func (p Provider) ScansOrdered() bool {
+	return true
+}
@@ -0,0 +1,11 @@
+package pebble
+
+import "github.com/alash3al/goukv"
+
+const (
+	name = "pebble"
+)
+
+func init() {
+	goukv.Register(name, Provider{})
+}
@@ -0,0 +1,215 @@
+package pebble
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/alash3al/goukv"
+	"github.com/cockroachdb/pebble"
+)
+
+const (
+	defaultCacheSize    = 8 << 20
+	defaultMemTableSize = 4 << 20
+)
+
+// Provider represents a driver
+type Provider struct {
+	db           *pebble.DB
+	writeOptions *pebble.WriteOptions
+}
+
+// Open implements goukv.Open
+func (p Provider) Open(dsn *goukv.DSN) (goukv.Provider, error) {
+	path := dsn.Hostname() + dsn.Path()
+
+	cacheSize := dsn.GetInt64("cache_size")
+	if cacheSize < 1 {
+		cacheSize = defaultCacheSize
+	}
+
+	memTableSize := dsn.GetInt64("memtable_size")
+	if memTableSize < 1 {
+		memTableSize = defaultMemTableSize
+	}
+
+	writeOptions := pebble.NoSync
+	if dsn.GetBool("sync_writes") {
+		writeOptions = pebble.Sync
+	}
+
+	cache := pebble.NewCache(cacheSize)
+	defer cache.Unref()
+
+	db, err := pebble.Open(path, &pebble.Options{
+		Cache:        cache,
+		MemTableSize: uint64(memTableSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Provider{
+		db:           db,
+		writeOptions: writeOptions,
+	}, nil
+}
+
+// Put implements goukv.Put
+func (p Provider) Put(e *goukv.Entry) error {
+	return p.db.Set(e.Key, EntryToValue(e).Bytes(), p.writeOptions)
+}
+
+// Batch perform multi put operation, empty value means *delete*
+func (p Provider) Batch(entries []*goukv.Entry) error {
+	batch := p.db.NewBatch()
+	defer batch.Close()
+
+	for _, entry := range entries {
+		var err error
+		if entry.Value == nil {
+			err = batch.Delete(entry.Key, nil)
+		} else {
+			err = batch.Set(entry.Key, EntryToValue(entry).Bytes(), nil)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return batch.Commit(p.writeOptions)
+}
+
+// Get implements goukv.Get
+func (p Provider) Get(k []byte) ([]byte, error) {
+	val, err := p.get(k)
+	if err != nil {
+		return nil, err
+	}
+
+	if val.IsExpired() {
+		return nil, goukv.ErrKeyExpired
+	}
+
+	return val.Value, nil
+}
+
+// TTL implements goukv.TTL
+func (p Provider) TTL(k []byte) (*time.Time, error) {
+	val, err := p.get(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return val.Expires, nil
+}
+
+// Delete implements goukv.Delete
+func (p Provider) Delete(k []byte) error {
+	return p.db.Delete(k, p.writeOptions)
+}
+
+// Close implements goukv.Close
+func (p Provider) Close() error {
+	return p.db.Close()
+}
+
+// Scan implements goukv.Scan
+func (p Provider) Scan(opts goukv.ScanOpts) error {
+	if opts.Scanner == nil {
+		return nil
+	}
+
+	iterOpts := &pebble.IterOptions{}
+	if len(opts.Prefix) > 0 {
+		iterOpts.LowerBound = opts.Prefix
+		iterOpts.UpperBound = prefixEnd(opts.Prefix)
+	}
+
+	iter, err := p.db.NewIter(iterOpts)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	var valid bool
+	var next func() bool
+
+	if opts.ReverseScan {
+		next = iter.Prev
+
+		if opts.Offset != nil {
+			// the key right after the offset, so that the offset itself is the first candidate
+			valid = iter.SeekLT(append(append([]byte{}, opts.Offset...), 0))
+		} else {
+			valid = iter.Last()
+		}
+	} else {
+		next = iter.Next
+
+		if opts.Offset != nil {
+			valid = iter.SeekGE(opts.Offset)
+		} else {
+			valid = iter.First()
+		}
+	}
+
+	for ; valid; valid = next() {
+		if !opts.IncludeOffset && opts.Offset != nil && bytes.Equal(iter.Key(), opts.Offset) {
+			continue
+		}
+
+		newK := make([]byte, len(iter.Key()))
+		newV := make([]byte, len(iter.Value()))
+
+		copy(newK, iter.Key())
+		copy(newV, iter.Value())
+
+		decodedValue := BytesToValue(newV)
+		if decodedValue.IsExpired() {
+			continue
+		}
+
+		if !opts.Scanner(newK, decodedValue.Value) {
+			break
+		}
+	}
+
+	return iter.Error()
+}
+
+// get fetches and decodes the value of the specified key
+func (p Provider) get(k []byte) (Value, error) {
+	b, closer, err := p.db.Get(k)
+	if err == pebble.ErrNotFound {
+		return Value{}, goukv.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return Value{}, err
+	}
+	defer closer.Close()
+
+	return BytesToValue(b), nil
+}
+
+// prefixEnd returns the smallest key greater than all keys having the specified prefix
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+
+	return nil
+}
+
+// ScansOrdered implements goukv.OrderedScanner
+func (p Provider) ScansOrdered() bool {
+	return true
+}
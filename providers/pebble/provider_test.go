@@ -0,0 +1,124 @@
+package pebble
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alash3al/goukv"
+)
+
+func openDBAndDo(fn func(db goukv.Provider)) error {
+	p := Provider{}
+	dsn, err := goukv.NewDSN("pebble://./db")
+	if err != nil {
+		return err
+	}
+	db, err := p.Open(dsn)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		db.Close()
+		os.RemoveAll("./db")
+	}()
+
+	fn(db)
+
+	return nil
+}
+
+func TestPutGet(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		entry := goukv.Entry{
+			Key:   []byte("k"),
+			Value: []byte("v"),
+		}
+		err := db.Put(&entry)
+		if err != nil {
+			t.Error(err)
+		}
+		val, err := db.Get(entry.Key)
+		if err != nil {
+			t.Error(err)
+		}
+		if string(val) != string(entry.Value) {
+			t.Errorf("expected (%s), found(%s)", string(entry.Value), string(val))
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestTTL(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		entry := goukv.Entry{
+			Key:   []byte("k"),
+			Value: []byte("v"),
+			TTL:   time.Second * 10,
+		}
+		err := db.Put(&entry)
+		if err != nil {
+			t.Error(err)
+		}
+		expiresAt, err := db.TTL(entry.Key)
+		if err != nil {
+			t.Error(err)
+		}
+		if !(expiresAt.Before(time.Now().Add(entry.TTL)) || expiresAt.Equal(time.Now().Add(entry.TTL))) {
+			t.Errorf("expected to be expires <= (%d), found (%d)", time.Now().Add(entry.TTL).Unix(), expiresAt.Unix())
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestBatchScan(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		err := db.Batch([]*goukv.Entry{
+			{Key: []byte("a/1"), Value: []byte("1")},
+			{Key: []byte("a/3"), Value: []byte("3")},
+			{Key: []byte("a/2"), Value: []byte("2")},
+			{Key: []byte("b/1"), Value: []byte("1")},
+		})
+		if err != nil {
+			t.Error(err)
+		}
+
+		scan := func(opts goukv.ScanOpts) string {
+			found := ""
+			opts.Scanner = func(k, v []byte) bool {
+				found += string(k) + ","
+				return true
+			}
+			if err := db.Scan(opts); err != nil {
+				t.Error(err)
+			}
+			return found
+		}
+
+		cases := map[string]goukv.ScanOpts{
+			"a/1,a/2,a/3,":     {Prefix: []byte("a/")},
+			"a/3,a/2,a/1,":     {Prefix: []byte("a/"), ReverseScan: true},
+			"a/3,b/1,":         {Offset: []byte("a/2")},
+			"a/2,a/3,b/1,":     {Offset: []byte("a/2"), IncludeOffset: true},
+			"a/1,":             {Offset: []byte("a/2"), ReverseScan: true},
+			"b/1,a/3,a/2,a/1,": {ReverseScan: true},
+		}
+
+		for expected, opts := range cases {
+			if found := scan(opts); found != expected {
+				t.Errorf("expected (%s), found (%s)", expected, found)
+			}
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
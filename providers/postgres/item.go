@@ -15,7 +15,6 @@ func (i Item) ExpiresAt() time.Time {
 }
 
 func (i Item) Expired() bool {
-	println(i.X)
 	if i.X < 1 {
 		return false
 	}
@@ -2,26 +2,46 @@ package postgres
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/alash3al/goukv"
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	_ "github.com/lib/pq"
 )
 
+// namespaceRegexp the valid namespace names, as they are used as a part of the table names,
+// they can't contain the `_` separator, so a nested namespace can't clash with a top level one
+var namespaceRegexp = regexp.MustCompile(`^[a-z0-9]+$`)
+
 // Provider represents a driver
 type Provider struct {
-	db    *sqlx.DB
-	table string
+	db        *sqlx.DB
+	table     string
+	ctx       context.Context
+	logger    goukv.Logger
+	slowQuery time.Duration
+
+	// namespaced whether the provider is a namespace sharing the connections pool of its parent
+	namespaced bool
 }
 
 // Open implements goukv.Open
 func (p Provider) Open(dsn *goukv.DSN) (goukv.Provider, error) {
+	logger, err := goukv.LoggerFromDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
 	driverDSN := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", dsn.Username(), dsn.Password(), dsn.Hostname(), dsn.Port(), dsn.Path())
 	db, err := sqlx.Connect("postgres", driverDSN)
 	if err != nil {
@@ -30,25 +50,15 @@ func (p Provider) Open(dsn *goukv.DSN) (goukv.Provider, error) {
 
 	table := dsn.GetString("table")
 
-	if _, err := db.Exec(`
-		CREATE EXTENSION IF NOT EXISTS pg_trgm;
-
-		CREATE TABLE IF NOT EXISTS ` + (table) + ` (
-			_id SERIAL PRIMARY KEY,
-			_k 	VARCHAR,
-			_v  TEXT,
-			_x  BIGINT DEFAULT 0
-		);
-
-		CREATE UNIQUE INDEX IF NOT EXISTS idx_` + (table) + `_k ON ` + (table) + `(_k);
-		CREATE INDEX IF NOT EXISTS idx_gintrgm_` + (table) + `_k ON ` + (table) + ` USING GIN(_k gin_trgm_ops);
-	`); err != nil {
+	if err := createTable(db, table); err != nil {
 		return nil, err
 	}
 
 	return &Provider{
-		db:    db,
-		table: table,
+		db:        db,
+		table:     table,
+		logger:    logger,
+		slowQuery: time.Duration(dsn.GetInt("slow_query_ms")) * time.Millisecond,
 	}, nil
 }
 
@@ -70,16 +80,76 @@ func (p Provider) Put(e *goukv.Entry) error {
 			SET _v = :_v,
 				_x = :_x
 	`
-	_, err := p.db.NamedExec(query, item)
+	ctx, done := p.statement(query)
+	defer done()
+
+	_, err := p.db.NamedExecContext(ctx, query, item)
 
 	return err
 }
 
+// CompareAndSwap implements goukv.CompareAndSwapper using single conditional statements,
+// so it is atomic across all the processes sharing the table
+func (p Provider) CompareAndSwap(e *goukv.Entry, old []byte) (bool, error) {
+	var expires int64
+	if e.TTL > 0 {
+		expires = time.Now().Add(e.TTL).Unix()
+	}
+
+	now := time.Now().Unix()
+
+	var query string
+	var args []interface{}
+
+	switch {
+	case old == nil && e.Value == nil:
+		_, err := p.Get(e.Key)
+		if err == goukv.ErrKeyNotFound || err == goukv.ErrKeyExpired {
+			return true, nil
+		}
+
+		return false, err
+	case old == nil:
+		// the conflicting row is only replaced if it is expired
+		query = `
+			INSERT INTO ` + (p.table) + `(_k, _v, _x) VALUES($1, $2, $3)
+			ON CONFLICT (_k) DO UPDATE
+				SET _v = EXCLUDED._v,
+					_x = EXCLUDED._x
+				WHERE ` + (p.table) + `._x > 0 AND ` + (p.table) + `._x <= $4
+		`
+		args = []interface{}{e.Key, e.Value, expires, now}
+	case e.Value == nil:
+		query = `DELETE FROM ` + (p.table) + ` WHERE _k = $1 AND _v = $2 AND (_x < 1 OR _x > $3)`
+		args = []interface{}{e.Key, old, now}
+	default:
+		query = `UPDATE ` + (p.table) + ` SET _v = $1, _x = $2 WHERE _k = $3 AND _v = $4 AND (_x < 1 OR _x > $5)`
+		args = []interface{}{e.Value, expires, e.Key, old, now}
+	}
+
+	ctx, done := p.statement(query)
+	defer done()
+
+	result, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+
+	return affected > 0, err
+}
+
 // Get implements goukv.Get
 func (p Provider) Get(k []byte) ([]byte, error) {
 	var item Item
 
-	err := p.db.Get(&item, `SELECT * FROM `+(p.table)+` WHERE _k = $1`, k)
+	query := `SELECT * FROM ` + (p.table) + ` WHERE _k = $1`
+
+	ctx, done := p.statement(query)
+	defer done()
+
+	err := p.db.GetContext(ctx, &item, query, k)
 	if err == sql.ErrNoRows {
 		return nil, goukv.ErrKeyNotFound
 	}
@@ -99,7 +169,12 @@ func (p Provider) Get(k []byte) ([]byte, error) {
 func (p Provider) TTL(k []byte) (*time.Time, error) {
 	var item Item
 
-	err := p.db.Get(&item, `SELECT * FROM `+(p.table)+` WHERE _k = $1`, k)
+	query := `SELECT * FROM ` + (p.table) + ` WHERE _k = $1`
+
+	ctx, done := p.statement(query)
+	defer done()
+
+	err := p.db.GetContext(ctx, &item, query, k)
 	if err == sql.ErrNoRows {
 		return nil, goukv.ErrKeyNotFound
 	}
@@ -118,7 +193,12 @@ func (p Provider) TTL(k []byte) (*time.Time, error) {
 
 // Delete implements goukv.Delete
 func (p Provider) Delete(k []byte) error {
-	_, err := p.db.Exec(`DELETE FROM `+(p.table)+` WHERE _k = $1`, k)
+	query := `DELETE FROM ` + (p.table) + ` WHERE _k = $1`
+
+	ctx, done := p.statement(query)
+	defer done()
+
+	_, err := p.db.ExecContext(ctx, query, k)
 	return err
 }
 
@@ -145,11 +225,37 @@ func (p Provider) Batch(entries []*goukv.Entry) error {
 	return nil
 }
 
-// Close implements goukv.Close
+// WithContext implements goukv.ContextBinder, the queries are bound to the specified context
+// and their SQL statements are attached to its span as events
+func (p Provider) WithContext(ctx context.Context) goukv.Provider {
+	p.ctx = ctx
+
+	return &p
+}
+
+// Close implements goukv.Close, it is a no-op for the namespaces as they share the connections pool
 func (p Provider) Close() error {
+	if p.namespaced {
+		return nil
+	}
+
 	return p.db.Close()
 }
 
+// Stats implements goukv.Stater
+func (p Provider) Stats() map[string]float64 {
+	stats := p.db.Stats()
+
+	return map[string]float64{
+		"max_open_connections":  float64(stats.MaxOpenConnections),
+		"open_connections":      float64(stats.OpenConnections),
+		"in_use_connections":    float64(stats.InUse),
+		"idle_connections":      float64(stats.Idle),
+		"wait_count":            float64(stats.WaitCount),
+		"wait_duration_seconds": stats.WaitDuration.Seconds(),
+	}
+}
+
 // Scan implements goukv.Scan
 func (p Provider) Scan(opts goukv.ScanOpts) error {
 	if opts.Scanner == nil {
@@ -166,13 +272,13 @@ func (p Provider) Scan(opts goukv.ScanOpts) error {
 	}
 
 	if len(opts.Offset) > 0 {
-		where = append(where, `_id >= (SELECT _id FROM `+(p.table)+` WHERE _k = $1)`)
 		args = append(args, string(opts.Offset))
+		where = append(where, fmt.Sprintf(`_id >= (SELECT _id FROM %s WHERE _k = $%d)`, p.table, len(args)))
 	}
 
 	if len(opts.Prefix) > 0 {
-		where = append(where, `_k LIKE $2`)
-		args = append(args, string(opts.Prefix)+"%")
+		args = append(args, escapeLike(string(opts.Prefix))+"%")
+		where = append(where, fmt.Sprintf(`_k LIKE $%d`, len(args)))
 	}
 
 	if len(where) > 0 {
@@ -181,7 +287,10 @@ func (p Provider) Scan(opts goukv.ScanOpts) error {
 
 	query += " ORDER BY _id " + sortOrder
 
-	rows, err := p.db.Queryx(query, args...)
+	ctx, done := p.statement(query)
+	rows, err := p.db.QueryxContext(ctx, query, args...)
+	done()
+
 	if err != nil {
 		return err
 	}
@@ -209,3 +318,104 @@ func (p Provider) Scan(opts goukv.ScanOpts) error {
 
 	return nil
 }
+
+// Namespace implements goukv.Namespacer, each namespace is stored in its own table named `<table>_<name>`,
+// so the name must only contain lowercase letters and digits. closing the returned provider doesn't close this one
+func (p Provider) Namespace(name string) (goukv.Provider, error) {
+	if !namespaceRegexp.MatchString(name) {
+		return nil, goukv.ErrInvalidNamespace
+	}
+
+	p.table = p.table + "_" + name
+	p.namespaced = true
+
+	if err := createTable(p.db, p.table); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Namespaces implements goukv.Namespacer, the nested namespaces aren't listed
+func (p Provider) Namespaces() ([]string, error) {
+	prefix := strings.ToLower(p.table) + "_"
+	tables := []string{}
+
+	err := p.db.Select(&tables, `
+		SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_name LIKE $1
+	`, escapeLike(prefix)+"%")
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, table := range tables {
+		if name := strings.TrimPrefix(table, prefix); namespaceRegexp.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// DropNamespace implements goukv.Namespacer
+func (p Provider) DropNamespace(name string) error {
+	if !namespaceRegexp.MatchString(name) {
+		return goukv.ErrInvalidNamespace
+	}
+
+	_, err := p.db.Exec(`DROP TABLE IF EXISTS ` + p.table + `_` + name)
+
+	return err
+}
+
+// statement attaches the specified SQL statement to the span of the bound context (if any)
+// as an event, then returns the context to run the statement with and a function to be called
+// once the statement is done, it logs the statement if it took longer than the `slow_query_ms` option
+func (p Provider) statement(query string) (context.Context, func()) {
+	ctx, start := p.ctx, time.Now()
+
+	if ctx == nil {
+		ctx = context.Background()
+	} else {
+		trace.SpanFromContext(ctx).AddEvent("sql", trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.statement", strings.TrimSpace(query)),
+		))
+	}
+
+	return ctx, func() {
+		if p.slowQuery < 1 || p.logger == nil {
+			return
+		}
+
+		if elapsed := time.Since(start); elapsed >= p.slowQuery {
+			p.logger.Log(ctx, slog.LevelWarn, "slow query", "provider", name, "statement", strings.TrimSpace(query), "duration", elapsed)
+		}
+	}
+}
+
+// escapeLike escapes the wildcards of the specified LIKE pattern, so it is matched literally
+func escapeLike(pattern string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(pattern)
+}
+
+// createTable creates the specified table and its indexes if they don't exist
+func createTable(db *sqlx.DB, table string) error {
+	_, err := db.Exec(`
+		CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+		CREATE TABLE IF NOT EXISTS ` + (table) + ` (
+			_id SERIAL PRIMARY KEY,
+			_k 	VARCHAR,
+			_v  TEXT,
+			_x  BIGINT DEFAULT 0
+		);
+
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_` + (table) + `_k ON ` + (table) + `(_k);
+		CREATE INDEX IF NOT EXISTS idx_gintrgm_` + (table) + `_k ON ` + (table) + ` USING GIN(_k gin_trgm_ops);
+	`)
+
+	return err
+}
@@ -1,10 +1,12 @@
 package postgres
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/encryption"
 )
 
 func openDBAndDo(fn func(db goukv.Provider)) error {
@@ -98,3 +100,140 @@ func TestTTL(t *testing.T) {
 		t.Error(err.Error())
 	}
 }
+
+func TestCompareAndSwap(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		cas := db.(goukv.CompareAndSwapper)
+		key := []byte("cas")
+		db.Delete(key)
+
+		steps := []struct {
+			value, old []byte
+			expected   bool
+		}{
+			{[]byte("a"), nil, true},
+			{[]byte("b"), nil, false},
+			{[]byte("b"), []byte("x"), false},
+			{[]byte("b"), []byte("a"), true},
+			{nil, []byte("a"), false},
+			{nil, []byte("b"), true},
+			{nil, nil, true},
+		}
+
+		for i, step := range steps {
+			swapped, err := cas.CompareAndSwap(&goukv.Entry{Key: key, Value: step.value}, step.old)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if swapped != step.expected {
+				t.Errorf("step (%d): expected (%v), found (%v)", i, step.expected, swapped)
+			}
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestPrefixScan(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		db.Batch([]*goukv.Entry{
+			{Key: []byte("scan_%:1"), Value: []byte("1")},
+			{Key: []byte("scan_%:2"), Value: []byte("2")},
+			{Key: []byte("scanx%:3"), Value: []byte("3")},
+			{Key: []byte("scan_x:4"), Value: []byte("4")},
+		})
+
+		// the wildcards of the prefix are matched literally
+		found := ""
+		err := db.Scan(goukv.ScanOpts{
+			Prefix: []byte("scan_%:"),
+			Scanner: func(k, v []byte) bool {
+				found += string(v)
+				return true
+			},
+		})
+		if err != nil || found != "12" {
+			t.Errorf("expected (12), found (%s, %v)", found, err)
+		}
+
+		keys, err := encryption.WrapKeys(db, encryption.KeyOpts{
+			Mode:   encryption.KeyDeterministic,
+			Secret: []byte("0123456789abcdef"),
+			Prefix: encryption.SeparatorPrefix(':', 1),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keys.Batch([]*goukv.Entry{
+			{Key: []byte("users:a"), Value: []byte("a")},
+			{Key: []byte("users:b"), Value: []byte("b")},
+			{Key: []byte("orders:c"), Value: []byte("c")},
+		})
+
+		scanned := map[string]string{}
+		err = keys.Scan(goukv.ScanOpts{
+			Prefix: []byte("users:"),
+			Scanner: func(k, v []byte) bool {
+				scanned[string(k)] = string(v)
+				return true
+			},
+		})
+		if err != nil || len(scanned) != 2 || scanned["users:a"] != "a" || scanned["users:b"] != "b" {
+			t.Errorf("unexpected scan result (%v, %v)", scanned, err)
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestNamespaces(t *testing.T) {
+	if _, err := (Provider{}).Namespace("a_b"); err != goukv.ErrInvalidNamespace {
+		t.Errorf("expected (%v), found (%v)", goukv.ErrInvalidNamespace, err)
+	}
+
+	err := openDBAndDo(func(db goukv.Provider) {
+		ns := goukv.Namespace(db, "nsa")
+		nested := goukv.Namespace(ns, "nsb")
+
+		if err := nested.Put(&goukv.Entry{Key: []byte("k"), Value: []byte("v")}); err != nil {
+			t.Fatal(err)
+		}
+
+		names, err := goukv.Namespaces(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, name := range names {
+			if name != "nsa" && strings.HasPrefix(name, "nsa") {
+				t.Errorf("expected the nested namespace not to be listed, found (%s)", name)
+			}
+		}
+
+		if names, err := goukv.Namespaces(ns); err != nil || len(names) != 1 || names[0] != "nsb" {
+			t.Errorf("expected ([nsb]), found (%v, %v)", names, err)
+		}
+
+		// the namespaces share the connections pool of their parent
+		if err := nested.Close(); err != nil {
+			t.Error(err)
+		}
+
+		if _, err := db.Get([]byte("k")); err != nil && err != goukv.ErrKeyNotFound {
+			t.Errorf("expected the parent to remain open, found (%v)", err)
+		}
+
+		goukv.DropNamespace(ns, "nsb")
+		goukv.DropNamespace(db, "nsa")
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
@@ -0,0 +1,11 @@
+package redis
+
+import "github.com/alash3al/goukv"
+
+const (
+	name = "redis"
+)
+
+func init() {
+	goukv.Register(name, Provider{})
+}
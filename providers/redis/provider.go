@@ -0,0 +1,317 @@
+package redis
+
+import (
+	"bytes"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alash3al/goukv"
+	"github.com/go-redis/redis/v7"
+)
+
+const (
+	defaultPort     = "6379"
+	defaultScanSize = 1000
+)
+
+// Provider represents a driver
+type Provider struct {
+	client   *redis.Client
+	scanSize int64
+}
+
+// Open implements goukv.Open
+func (p Provider) Open(dsn *goukv.DSN) (goukv.Provider, error) {
+	port := dsn.Port()
+	if port == "" {
+		port = defaultPort
+	}
+
+	db, _ := strconv.Atoi(strings.Trim(dsn.Path(), "/"))
+
+	scanSize := dsn.GetInt64("scan_size")
+	if scanSize < 1 {
+		scanSize = defaultScanSize
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     net.JoinHostPort(dsn.Hostname(), port),
+		Password: dsn.Password(),
+		DB:       db,
+		PoolSize: dsn.GetInt("pool_size"),
+	})
+
+	if err := client.Ping().Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &Provider{
+		client:   client,
+		scanSize: scanSize,
+	}, nil
+}
+
+// Put implements goukv.Put
+func (p Provider) Put(e *goukv.Entry) error {
+	return p.client.Do(setArgs(e)...).Err()
+}
+
+// Batch perform multi put operation, empty value means *delete*
+func (p Provider) Batch(entries []*goukv.Entry) error {
+	_, err := p.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		for _, entry := range entries {
+			if entry.Value == nil {
+				pipe.Del(string(entry.Key))
+			} else {
+				pipe.Do(setArgs(entry)...)
+			}
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+// Get implements goukv.Get
+func (p Provider) Get(k []byte) ([]byte, error) {
+	val, err := p.client.Get(string(k)).Bytes()
+	if err == redis.Nil {
+		return nil, goukv.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return val, nil
+}
+
+// TTL implements goukv.TTL
+func (p Provider) TTL(k []byte) (*time.Time, error) {
+	ttl, err := p.client.PTTL(string(k)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// -2 means the key doesn't exist, -1 means the key has no expiration
+	switch ttl {
+	case -2:
+		return nil, goukv.ErrKeyNotFound
+	case -1:
+		return nil, nil
+	}
+
+	expiresAt := time.Now().Add(ttl)
+
+	return &expiresAt, nil
+}
+
+// Delete implements goukv.Delete
+func (p Provider) Delete(k []byte) error {
+	return p.client.Del(string(k)).Err()
+}
+
+// CompareAndSwap implements goukv.CompareAndSwapper using `WATCH`/`MULTI`, the swap fails
+// if the key is changed by another client between reading and writing it
+func (p Provider) CompareAndSwap(e *goukv.Entry, old []byte) (bool, error) {
+	key := string(e.Key)
+	swapped := false
+
+	err := p.client.Watch(func(tx *redis.Tx) error {
+		current, err := tx.Get(key).Bytes()
+		if err == redis.Nil {
+			current, err = nil, nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if (old == nil) != (current == nil) || (old != nil && !bytes.Equal(current, old)) {
+			return nil
+		}
+
+		_, err = tx.TxPipelined(func(pipe redis.Pipeliner) error {
+			if e.Value == nil {
+				pipe.Del(key)
+			} else {
+				pipe.Do(setArgs(e)...)
+			}
+
+			return nil
+		})
+
+		swapped = err == nil
+
+		return err
+	}, key)
+
+	if err == redis.TxFailedErr {
+		return false, nil
+	}
+
+	return swapped, err
+}
+
+// Close implements goukv.Close
+func (p Provider) Close() error {
+	return p.client.Close()
+}
+
+// Scan implements goukv.Scan
+// redis doesn't maintain any order of its keys, so the keys are streamed in the
+// `SCAN` order unless `ReverseScan` or `Offset` is requested, then all the matching
+// keys are collected and sorted on the client side first, and as `SCAN` may return
+// a key more than once, the keys already seen are skipped.
+func (p Provider) Scan(opts goukv.ScanOpts) error {
+	if opts.Scanner == nil {
+		return nil
+	}
+
+	match := escapePattern(opts.Prefix) + "*"
+	seen := map[string]bool{}
+
+	if !opts.ReverseScan && opts.Offset == nil {
+		var cursor uint64
+
+		for {
+			keys, next, err := p.client.Scan(cursor, match, p.scanSize).Result()
+			if err != nil {
+				return err
+			}
+
+			if more, err := p.emit(unseen(keys, seen), opts.Scanner); err != nil || !more {
+				return err
+			}
+
+			if cursor = next; cursor == 0 {
+				return nil
+			}
+		}
+	}
+
+	keys := []string{}
+	iter := p.client.Scan(0, match, p.scanSize).Iterator()
+
+	for iter.Next() {
+		keys = append(keys, iter.Val())
+	}
+
+	keys = unseen(keys, seen)
+
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	if opts.ReverseScan {
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	} else {
+		sort.Strings(keys)
+	}
+
+	if opts.Offset != nil {
+		keys = keys[sort.Search(len(keys), func(i int) bool {
+			cmp := bytes.Compare([]byte(keys[i]), opts.Offset)
+			if opts.ReverseScan {
+				cmp = -cmp
+			}
+
+			return cmp > 0 || (cmp == 0 && opts.IncludeOffset)
+		}):]
+	}
+
+	for len(keys) > 0 {
+		size := int(p.scanSize)
+		if size > len(keys) {
+			size = len(keys)
+		}
+
+		if more, err := p.emit(keys[:size], opts.Scanner); err != nil || !more {
+			return err
+		}
+
+		keys = keys[size:]
+	}
+
+	return nil
+}
+
+// emit fetches the values of the specified keys and passes them to the scanner,
+// it returns false if the scanner asked to stop
+func (p Provider) emit(keys []string, scanner goukv.Scanner) (bool, error) {
+	if len(keys) < 1 {
+		return true, nil
+	}
+
+	vals, err := p.client.MGet(keys...).Result()
+	if err != nil {
+		return false, err
+	}
+
+	for i, val := range vals {
+		// the key has been deleted or expired after being scanned
+		str, ok := val.(string)
+		if !ok {
+			continue
+		}
+
+		if !scanner([]byte(keys[i]), []byte(str)) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// unseen returns the specified keys that aren't in the seen set, and adds them to it
+func unseen(keys []string, seen map[string]bool) []string {
+	result := keys[:0]
+
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		result = append(result, key)
+	}
+
+	return result
+}
+
+// setArgs builds a `SET` command for the specified entry
+func setArgs(e *goukv.Entry) []interface{} {
+	args := []interface{}{"SET", string(e.Key), e.Value}
+
+	if e.TTL > 0 {
+		ms := int64(e.TTL / time.Millisecond)
+		if ms < 1 {
+			ms = 1
+		}
+
+		args = append(args, "PX", ms)
+	}
+
+	return args
+}
+
+// escapePattern escapes the glob special chars of the specified prefix
+func escapePattern(prefix []byte) string {
+	var buf strings.Builder
+
+	for _, c := range prefix {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			buf.WriteByte('\\')
+		}
+
+		buf.WriteByte(c)
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,186 @@
+package redis
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alash3al/goukv"
+	"github.com/alicebob/miniredis/v2"
+)
+
+func openDBAndDo(fn func(db goukv.Provider)) error {
+	server, err := miniredis.Run()
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+
+	p := Provider{}
+	dsn, err := goukv.NewDSN("redis://" + server.Addr() + "/0?scan_size=2")
+	if err != nil {
+		return err
+	}
+	db, err := p.Open(dsn)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		db.Close()
+	}()
+
+	fn(db)
+
+	return nil
+}
+
+func TestPutGet(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		entry := goukv.Entry{
+			Key:   []byte("k"),
+			Value: []byte("v"),
+		}
+		err := db.Put(&entry)
+		if err != nil {
+			t.Error(err)
+		}
+		val, err := db.Get(entry.Key)
+		if err != nil {
+			t.Error(err)
+		}
+		if string(val) != string(entry.Value) {
+			t.Errorf("expected (%s), found(%s)", string(entry.Value), string(val))
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestTTL(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		entry := goukv.Entry{
+			Key:   []byte("k"),
+			Value: []byte("v"),
+			TTL:   time.Second * 10,
+		}
+		err := db.Put(&entry)
+		if err != nil {
+			t.Error(err)
+		}
+		expiresAt, err := db.TTL(entry.Key)
+		if err != nil {
+			t.Error(err)
+		}
+		if !(expiresAt.Before(time.Now().Add(entry.TTL)) || expiresAt.Equal(time.Now().Add(entry.TTL))) {
+			t.Errorf("expected to be expires <= (%d), found (%d)", time.Now().Add(entry.TTL).Unix(), expiresAt.Unix())
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestBatchScan(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		err := db.Batch([]*goukv.Entry{
+			{Key: []byte("a/1"), Value: []byte("1")},
+			{Key: []byte("a/3"), Value: []byte("3")},
+			{Key: []byte("a/2"), Value: []byte("2")},
+			{Key: []byte("b/1"), Value: []byte("1")},
+		})
+		if err != nil {
+			t.Error(err)
+		}
+
+		scan := func(opts goukv.ScanOpts) string {
+			found := ""
+			opts.Scanner = func(k, v []byte) bool {
+				found += string(k) + ","
+				return true
+			}
+			if err := db.Scan(opts); err != nil {
+				t.Error(err)
+			}
+			return found
+		}
+
+		// keys are streamed unordered when neither an offset nor a reverse scan is requested
+		unordered := strings.Split(strings.TrimSuffix(scan(goukv.ScanOpts{Prefix: []byte("a/")}), ","), ",")
+		sort.Strings(unordered)
+		if found := strings.Join(unordered, ","); found != "a/1,a/2,a/3" {
+			t.Errorf("expected (%s), found (%s)", "a/1,a/2,a/3", found)
+		}
+
+		cases := map[string]goukv.ScanOpts{
+			"a/3,a/2,a/1,":     {Prefix: []byte("a/"), ReverseScan: true},
+			"a/3,b/1,":         {Offset: []byte("a/2")},
+			"a/2,a/3,b/1,":     {Offset: []byte("a/2"), IncludeOffset: true},
+			"a/1,":             {Offset: []byte("a/2"), ReverseScan: true},
+			"b/1,a/3,a/2,a/1,": {ReverseScan: true},
+		}
+
+		for expected, opts := range cases {
+			if found := scan(opts); found != expected {
+				t.Errorf("expected (%s), found (%s)", expected, found)
+			}
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestUnseen(t *testing.T) {
+	seen := map[string]bool{}
+
+	if keys := unseen([]string{"k1", "k2", "k1"}, seen); strings.Join(keys, ",") != "k1,k2" {
+		t.Errorf("expected (k1,k2), found (%v)", keys)
+	}
+
+	// a later SCAN page returning a key again
+	if keys := unseen([]string{"k2", "k3"}, seen); strings.Join(keys, ",") != "k3" {
+		t.Errorf("expected (k3), found (%v)", keys)
+	}
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	err := openDBAndDo(func(db goukv.Provider) {
+		cas := db.(goukv.CompareAndSwapper)
+		key := []byte("cas")
+		db.Delete(key)
+
+		steps := []struct {
+			value, old []byte
+			expected   bool
+		}{
+			{[]byte("a"), nil, true},
+			{[]byte("b"), nil, false},
+			{[]byte("b"), []byte("x"), false},
+			{[]byte("b"), []byte("a"), true},
+			{nil, []byte("a"), false},
+			{nil, []byte("b"), true},
+			{nil, nil, true},
+		}
+
+		for i, step := range steps {
+			swapped, err := cas.CompareAndSwap(&goukv.Entry{Key: key, Value: step.value}, step.old)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if swapped != step.expected {
+				t.Errorf("step (%d): expected (%v), found (%v)", i, step.expected, swapped)
+			}
+		}
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
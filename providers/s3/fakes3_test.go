@@ -0,0 +1,188 @@
+package s3
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeObject represents an object stored in the fake server
+type fakeObject struct {
+	data     []byte
+	meta     http.Header
+	modified time.Time
+}
+
+// fakeS3 is a minimal in-memory path-style S3 server, it only implements
+// the requests issued by the provider and doesn't verify any signature
+type fakeS3 struct {
+	sync.Mutex
+	buckets map[string]map[string]*fakeObject
+}
+
+// newFakeS3 starts a new fake server
+func newFakeS3() *httptest.Server {
+	return httptest.NewServer(&fakeS3{
+		buckets: map[string]map[string]*fakeObject{},
+	})
+}
+
+// ServeHTTP implements http.Handler
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	bucket, key := parts[0], ""
+	if len(parts) > 1 {
+		key = parts[1]
+	}
+
+	objects, exists := s.buckets[bucket]
+	query := r.URL.Query()
+
+	switch {
+	case key == "" && r.Method == http.MethodPut:
+		s.buckets[bucket] = map[string]*fakeObject{}
+	case !exists:
+		writeError(w, http.StatusNotFound, "NoSuchBucket")
+	case key == "" && r.Method == http.MethodHead:
+	case key == "" && r.Method == http.MethodGet && query.Get("list-type") == "2":
+		s.list(w, objects, query.Get("prefix"), query.Get("start-after"))
+	case key == "" && r.Method == http.MethodPost && query["delete"] != nil:
+		s.deleteMulti(w, r, objects)
+	case r.Method == http.MethodPut:
+		data, _ := ioutil.ReadAll(decodeBody(r))
+		meta := http.Header{}
+		for k, v := range r.Header {
+			if strings.HasPrefix(k, "X-Amz-Meta-") {
+				meta[k] = v
+			}
+		}
+		objects[key] = &fakeObject{data: data, meta: meta, modified: time.Now()}
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		obj, found := objects[key]
+		if !found {
+			writeError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		for k, v := range obj.meta {
+			w.Header()[k] = v
+		}
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+		w.Header().Set("Last-Modified", obj.modified.UTC().Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			w.Write(obj.data)
+		}
+	case r.Method == http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// list implements ListObjectsV2 without pagination
+func (s *fakeS3) list(w http.ResponseWriter, objects map[string]*fakeObject, prefix, startAfter string) {
+	type content struct {
+		Key          string
+		LastModified string
+		ETag         string
+		Size         int
+	}
+
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Prefix      string
+		KeyCount    int
+		MaxKeys     int
+		IsTruncated bool
+		Contents    []content
+	}{Prefix: prefix, MaxKeys: 1000}
+
+	keys := []string{}
+	for k := range objects {
+		if strings.HasPrefix(k, prefix) && k > startAfter {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		result.Contents = append(result.Contents, content{
+			Key:          k,
+			LastModified: objects[k].modified.UTC().Format(time.RFC3339),
+			ETag:         `"etag"`,
+			Size:         len(objects[k].data),
+		})
+	}
+	result.KeyCount = len(keys)
+
+	xml.NewEncoder(w).Encode(result)
+}
+
+// deleteMulti implements DeleteObjects
+func (s *fakeS3) deleteMulti(w http.ResponseWriter, r *http.Request, objects map[string]*fakeObject) {
+	var req struct {
+		Objects []struct {
+			Key string
+		} `xml:"Object"`
+	}
+
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "MalformedXML")
+		return
+	}
+
+	for _, obj := range req.Objects {
+		delete(objects, obj.Key)
+	}
+
+	w.Write([]byte(`<DeleteResult></DeleteResult>`))
+}
+
+// decodeBody decodes the "aws-chunked" streaming payload if any
+func decodeBody(r *http.Request) io.Reader {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return r.Body
+	}
+
+	pr, pw := io.Pipe()
+	go (func() {
+		rd := bufio.NewReader(r.Body)
+		for {
+			line, err := rd.ReadString('\n')
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			size, _ := strconv.ParseInt(strings.SplitN(strings.TrimSpace(line), ";", 2)[0], 16, 64)
+			if size == 0 {
+				pw.Close()
+				return
+			}
+			io.CopyN(pw, rd, size)
+			rd.ReadString('\n')
+		}
+	})()
+
+	return pr
+}
+
+// writeError writes an S3 error response
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	w.Write([]byte(`<Error><Code>` + code + `</Code><Message>` + code + `</Message></Error>`))
+}
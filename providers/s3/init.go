@@ -0,0 +1,11 @@
+package s3
+
+import "github.com/alash3al/goukv"
+
+const (
+	name = "s3"
+)
+
+func init() {
+	goukv.Register(name, Provider{})
+}
@@ -0,0 +1,327 @@
+package s3
+
+import (
+	"context"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alash3al/goukv"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const (
+	metaExpires        = "Goukv-Expires"
+	defaultConcurrency = 8
+)
+
+// Provider represents a driver
+type Provider struct {
+	client      *minio.Client
+	bucket      string
+	concurrency int
+}
+
+// Open implements goukv.Open
+func (p Provider) Open(dsn *goukv.DSN) (goukv.Provider, error) {
+	endpoint := dsn.Hostname()
+	if dsn.Port() != "" {
+		endpoint += ":" + dsn.Port()
+	}
+
+	bucket := strings.Trim(dsn.Path(), "/")
+
+	concurrency := dsn.GetInt("concurrency")
+	if concurrency < 1 {
+		concurrency = defaultConcurrency
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(dsn.Username(), dsn.Password(), ""),
+		Secure: dsn.GetBool("secure"),
+		Region: dsn.GetString("region"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	exists, err := client.BucketExists(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		if err := client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{Region: dsn.GetString("region")}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Provider{
+		client:      client,
+		bucket:      bucket,
+		concurrency: concurrency,
+	}, nil
+}
+
+// Put implements goukv.Put
+func (p Provider) Put(e *goukv.Entry) error {
+	opts := minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+	}
+
+	if e.TTL > 0 {
+		opts.UserMetadata = map[string]string{
+			metaExpires: strconv.FormatInt(time.Now().Add(e.TTL).UnixNano(), 10),
+		}
+	}
+
+	_, err := p.client.PutObject(
+		context.Background(),
+		p.bucket,
+		string(e.Key),
+		strings.NewReader(string(e.Value)),
+		int64(len(e.Value)),
+		opts,
+	)
+
+	return err
+}
+
+// Batch perform multi put operation, empty value means *delete*
+// the puts are sent in parallel and the deletes are sent using a single `DeleteObjects` request,
+// so only the last entry of a duplicated key is applied, the same as if they were applied in order.
+func (p Provider) Batch(entries []*goukv.Entry) error {
+	var (
+		wg       sync.WaitGroup
+		errLock  sync.Mutex
+		firstErr error
+	)
+
+	setErr := func(err error) {
+		errLock.Lock()
+		defer errLock.Unlock()
+
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	sem := make(chan struct{}, p.concurrency)
+	deletes := make(chan minio.ObjectInfo, len(entries))
+
+	last := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		last[string(entry.Key)] = i
+	}
+
+	for i, entry := range entries {
+		if last[string(entry.Key)] != i {
+			continue
+		}
+
+		if entry.Value == nil {
+			deletes <- minio.ObjectInfo{Key: string(entry.Key)}
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go (func(entry *goukv.Entry) {
+			defer wg.Done()
+			defer (func() { <-sem })()
+
+			if err := p.Put(entry); err != nil {
+				setErr(err)
+			}
+		})(entry)
+	}
+
+	close(deletes)
+
+	for removeErr := range p.client.RemoveObjects(context.Background(), p.bucket, deletes, minio.RemoveObjectsOptions{}) {
+		setErr(removeErr.Err)
+	}
+
+	wg.Wait()
+
+	return firstErr
+}
+
+// Get implements goukv.Get
+func (p Provider) Get(k []byte) ([]byte, error) {
+	val, expires, err := p.get(string(k))
+	if err != nil {
+		return nil, err
+	}
+
+	if isExpired(expires) {
+		return nil, goukv.ErrKeyExpired
+	}
+
+	return val, nil
+}
+
+// TTL implements goukv.TTL
+func (p Provider) TTL(k []byte) (*time.Time, error) {
+	info, err := p.client.StatObject(context.Background(), p.bucket, string(k), minio.StatObjectOptions{})
+	if isNotFound(err) {
+		return nil, goukv.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parseExpires(info.UserMetadata), nil
+}
+
+// Delete implements goukv.Delete
+func (p Provider) Delete(k []byte) error {
+	return p.client.RemoveObject(context.Background(), p.bucket, string(k), minio.RemoveObjectOptions{})
+}
+
+// Close implements goukv.Close
+func (p Provider) Close() error {
+	return nil
+}
+
+// Scan implements goukv.Scan
+// objects are listed in ascending order only, so a `ReverseScan` loads all the matching keys first.
+func (p Provider) Scan(opts goukv.ScanOpts) error {
+	if opts.Scanner == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listOpts := minio.ListObjectsOptions{
+		Prefix:    string(opts.Prefix),
+		Recursive: true,
+	}
+
+	if opts.Offset != nil && !opts.ReverseScan {
+		listOpts.StartAfter = string(opts.Offset)
+	}
+
+	// `StartAfter` is exclusive, so the offset itself has to be fetched on its own
+	if opts.Offset != nil && opts.IncludeOffset && !opts.ReverseScan && strings.HasPrefix(string(opts.Offset), string(opts.Prefix)) {
+		if more, err := p.emit(string(opts.Offset), opts.Scanner); err != nil || !more {
+			return err
+		}
+	}
+
+	keys := []string{}
+
+	for obj := range p.client.ListObjects(ctx, p.bucket, listOpts) {
+		if obj.Err != nil {
+			return obj.Err
+		}
+
+		if !opts.ReverseScan {
+			if more, err := p.emit(obj.Key, opts.Scanner); err != nil || !more {
+				return err
+			}
+
+			continue
+		}
+
+		if opts.Offset != nil && (obj.Key > string(opts.Offset) || (obj.Key == string(opts.Offset) && !opts.IncludeOffset)) {
+			continue
+		}
+
+		keys = append(keys, obj.Key)
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+
+	for _, k := range keys {
+		if more, err := p.emit(k, opts.Scanner); err != nil || !more {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// emit fetches the specified key and passes it to the scanner,
+// it returns false if the scanner asked to stop
+func (p Provider) emit(k string, scanner goukv.Scanner) (bool, error) {
+	val, expires, err := p.get(k)
+	if err == goukv.ErrKeyNotFound {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if isExpired(expires) {
+		return true, nil
+	}
+
+	return scanner([]byte(k), val), nil
+}
+
+// get fetches the value and the expiration time of the specified key
+func (p Provider) get(k string) ([]byte, *time.Time, error) {
+	obj, err := p.client.GetObject(context.Background(), p.bucket, k, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer obj.Close()
+
+	info, err := obj.Stat()
+	if isNotFound(err) {
+		return nil, nil, goukv.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	val, err := ioutil.ReadAll(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return val, parseExpires(info.UserMetadata), nil
+}
+
+// parseExpires extracts the expiration time from the specified object metadata
+func parseExpires(meta minio.StringMap) *time.Time {
+	expires, _ := strconv.ParseInt(meta[metaExpires], 10, 64)
+	if expires < 1 {
+		return nil
+	}
+
+	t := time.Unix(0, expires)
+
+	return &t
+}
+
+// isExpired whether the specified expiration time has been reached or not
+func isExpired(expires *time.Time) bool {
+	if expires == nil {
+		return false
+	}
+
+	return time.Now().After(*expires) || time.Now().Equal(*expires)
+}
+
+// isNotFound whether the specified error means that the object doesn't exist
+func isNotFound(err error) bool {
+	return err != nil && minio.ToErrorResponse(err).Code == "NoSuchKey"
+}
+
+// ScansOrdered implements goukv.OrderedScanner
+func (p Provider) ScansOrdered() bool {
+	return true
+}
@@ -0,0 +1,11 @@
+package sqlite
+
+import "github.com/alash3al/goukv"
+
+const (
+	name = "sqlite"
+)
+
+func init() {
+	goukv.Register(name, Provider{})
+}
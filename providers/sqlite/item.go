@@ -0,0 +1,27 @@
+package sqlite
+
+import "time"
+
+// Item represents a row in the kv table
+type Item struct {
+	K []byte `db:"_k"`
+	V []byte `db:"_v"`
+	X int64  `db:"_x"`
+}
+
+// ExpiresAt returns the expiration time of the item
+func (i Item) ExpiresAt() time.Time {
+	return time.Unix(i.X, 0)
+}
+
+// Expired whether the item is expired or not
+func (i Item) Expired() bool {
+	if i.X < 1 {
+		return false
+	}
+
+	expiresAt := i.ExpiresAt()
+	now := time.Now()
+
+	return now.After(expiresAt) || now.Equal(expiresAt)
+}
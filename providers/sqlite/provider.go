@@ -0,0 +1,262 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alash3al/goukv"
+	"github.com/jmoiron/sqlx"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	defaultTable       = "goukv"
+	defaultBusyTimeout = 5000
+)
+
+// Provider represents a driver
+type Provider struct {
+	db    *sqlx.DB
+	table string
+}
+
+// Open implements goukv.Open
+func (p Provider) Open(dsn *goukv.DSN) (goukv.Provider, error) {
+	path := dsn.Hostname() + dsn.Path()
+	table := dsn.GetString("table")
+	busyTimeout := dsn.GetInt("busy_timeout")
+
+	if table == "" {
+		table = defaultTable
+	}
+
+	if busyTimeout < 1 {
+		busyTimeout = defaultBusyTimeout
+	}
+
+	driverDSN := fmt.Sprintf("file:%s?_busy_timeout=%d", path, busyTimeout)
+	if dsn.GetBool("wal") {
+		driverDSN += "&_journal_mode=WAL"
+	}
+
+	db, err := sqlx.Connect("sqlite3", driverDSN)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS ` + (table) + ` (
+			_k BLOB PRIMARY KEY,
+			_v BLOB,
+			_x INTEGER DEFAULT 0
+		) WITHOUT ROWID;
+	`); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Provider{
+		db:    db,
+		table: table,
+	}, nil
+}
+
+// Put implements goukv.Put
+func (p Provider) Put(e *goukv.Entry) error {
+	return p.put(p.db, e)
+}
+
+// Get implements goukv.Get
+func (p Provider) Get(k []byte) ([]byte, error) {
+	var item Item
+
+	err := p.db.Get(&item, `SELECT * FROM `+(p.table)+` WHERE _k = ?`, k)
+	if err == sql.ErrNoRows {
+		return nil, goukv.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if item.Expired() {
+		return nil, goukv.ErrKeyExpired
+	}
+
+	return item.V, nil
+}
+
+// TTL implements goukv.TTL
+func (p Provider) TTL(k []byte) (*time.Time, error) {
+	var item Item
+
+	err := p.db.Get(&item, `SELECT * FROM `+(p.table)+` WHERE _k = ?`, k)
+	if err == sql.ErrNoRows {
+		return nil, goukv.ErrKeyNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if item.X > 0 {
+		expiresAt := item.ExpiresAt()
+		return &expiresAt, nil
+	}
+
+	return nil, nil
+}
+
+// Delete implements goukv.Delete
+func (p Provider) Delete(k []byte) error {
+	return p.delete(p.db, k)
+}
+
+// Batch perform multi put operation, empty value means *delete*
+func (p Provider) Batch(entries []*goukv.Entry) error {
+	tx, err := p.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.Value == nil {
+			err = p.delete(tx, entry.Key)
+		} else {
+			err = p.put(tx, entry)
+		}
+
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Close implements goukv.Close
+func (p Provider) Close() error {
+	return p.db.Close()
+}
+
+// Scan implements goukv.Scan
+func (p Provider) Scan(opts goukv.ScanOpts) error {
+	if opts.Scanner == nil {
+		return nil
+	}
+
+	query := `SELECT * FROM ` + (p.table) + ``
+	where := []string{}
+	sortOrder := "ASC"
+	args := []interface{}{}
+
+	if opts.ReverseScan {
+		sortOrder = "DESC"
+	}
+
+	if len(opts.Offset) > 0 {
+		op := ">"
+		if opts.ReverseScan {
+			op = "<"
+		}
+
+		if opts.IncludeOffset {
+			op += "="
+		}
+
+		where = append(where, `_k `+op+` ?`)
+		args = append(args, opts.Offset)
+	}
+
+	if len(opts.Prefix) > 0 {
+		where = append(where, `_k >= ?`)
+		args = append(args, opts.Prefix)
+
+		if end := prefixEnd(opts.Prefix); end != nil {
+			where = append(where, `_k < ?`)
+			args = append(args, end)
+		}
+	}
+
+	if len(where) > 0 {
+		query += " WHERE (" + strings.Join(where, ") AND (") + ")"
+	}
+
+	query += " ORDER BY _k " + sortOrder
+
+	rows, err := p.db.Queryx(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item Item
+
+		if err := rows.StructScan(&item); err != nil {
+			return err
+		}
+
+		if item.Expired() {
+			continue
+		}
+
+		if !opts.Scanner(item.K, item.V) {
+			break
+		}
+	}
+
+	return rows.Err()
+}
+
+// put inserts or replaces the specified entry using the specified executor
+func (p Provider) put(exec sqlx.Execer, e *goukv.Entry) error {
+	item := Item{
+		K: e.Key,
+		V: e.Value,
+		X: 0,
+	}
+
+	if e.TTL > 0 {
+		item.X = time.Now().Add(e.TTL).Unix()
+	}
+
+	_, err := exec.Exec(`
+		INSERT INTO `+(p.table)+`(_k, _v, _x) VALUES(?, ?, ?)
+		ON CONFLICT (_k) DO UPDATE
+			SET _v = excluded._v,
+				_x = excluded._x
+	`, item.K, item.V, item.X)
+
+	return err
+}
+
+// delete removes the specified key using the specified executor
+func (p Provider) delete(exec sqlx.Execer, k []byte) error {
+	_, err := exec.Exec(`DELETE FROM `+(p.table)+` WHERE _k = ?`, k)
+	return err
+}
+
+// prefixEnd returns the smallest key greater than all keys having the specified prefix
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+
+	return nil
+}
+
+// ScansOrdered implements goukv.OrderedScanner
+func (p Provider) ScansOrdered() bool {
+	return true
+}
@@ -0,0 +1,412 @@
+// Package queue implements durable FIFO work-queues with visibility timeouts on top of goukv providers.
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/alash3al/goukv"
+)
+
+const (
+	keyPrefix = "queue:"
+
+	defaultVisibilityTimeout = 30 * time.Second
+	defaultMaxAttempts       = 5
+	defaultPollInterval      = time.Second
+	scanPage                 = 100
+	idSuffixLength           = 8
+)
+
+// error related variables
+var (
+	ErrUnorderedProvider = errors.New("the provider doesn't support ordered scans, see goukv.OrderedScanner")
+	ErrEmpty             = errors.New("there are no visible messages")
+	ErrLeaseLost         = errors.New("the message has been delivered again or removed since it was dequeued")
+)
+
+// locks the dequeue locks of the queues, by their keys prefix
+var locks sync.Map
+
+// sequence the last issued sequence within the process
+var sequence = struct {
+	sync.Mutex
+	last int64
+}{}
+
+// Opts the options of a queue
+type Opts struct {
+	// VisibilityTimeout how long a dequeued message stays invisible to the other consumers
+	// before it is delivered again unless it is acked, 30 seconds by default
+	VisibilityTimeout time.Duration
+
+	// MaxAttempts how many times a message is delivered before it is dead-lettered, 5 by default
+	MaxAttempts int
+
+	// PollInterval how often DequeueWait polls the provider if it doesn't implement goukv.Watcher,
+	// it is also used to notice the expired leases otherwise, 1 second by default
+	PollInterval time.Duration
+}
+
+// Message a queued message
+type Message struct {
+	ID         string    `json:"-"`
+	Body       []byte    `json:"body"`
+	Attempts   int       `json:"attempts"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+}
+
+// Queue a FIFO queue stored using the following keys:
+//   - `queue:<url escaped name>:m:<sequence>` holding the message
+//   - `queue:<url escaped name>:l:<sequence>` the in-flight lease of the message, it expires (Entry.TTL)
+//     after the visibility timeout, so the message becomes visible again
+//   - `queue:<url escaped name>:d:<sequence>` holding the dead-lettered message
+//
+// where the sequence is the zero padded enqueue time in nanoseconds followed by a random suffix, so the messages
+// are scanned in order and the messages enqueued by different processes at the same time don't overwrite each other.
+// the dequeues are serialized within the process only, so the consumers of other processes may receive the
+// same message, i.e: the delivery is at-least-once
+type Queue struct {
+	provider goukv.Provider
+	opts     Opts
+	messages []byte
+	leases   []byte
+	dead     []byte
+	lock     *sync.Mutex
+}
+
+// New returns the queue of the specified name, goukv.ScansOrdered must report the provider as ordered,
+// otherwise ErrUnorderedProvider is returned
+func New(p goukv.Provider, name string, opts Opts) (*Queue, error) {
+	if !goukv.ScansOrdered(p) {
+		return nil, ErrUnorderedProvider
+	}
+
+	if opts.VisibilityTimeout <= 0 {
+		opts.VisibilityTimeout = defaultVisibilityTimeout
+	}
+
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+
+	prefix := keyPrefix + url.QueryEscape(name)
+	lock, _ := locks.LoadOrStore(prefix, &sync.Mutex{})
+
+	return &Queue{
+		provider: p,
+		opts:     opts,
+		messages: []byte(prefix + ":m:"),
+		leases:   []byte(prefix + ":l:"),
+		dead:     []byte(prefix + ":d:"),
+		lock:     lock.(*sync.Mutex),
+	}, nil
+}
+
+// Enqueue appends the specified body to the queue and returns the message id
+func (q *Queue) Enqueue(body []byte) (string, error) {
+	id, err := nextID()
+	if err != nil {
+		return "", err
+	}
+
+	msg := &Message{
+		ID:         id,
+		Body:       body,
+		EnqueuedAt: time.Now(),
+	}
+
+	if err := q.provider.Put(&goukv.Entry{Key: q.key(q.messages, msg.ID), Value: encode(msg)}); err != nil {
+		return "", err
+	}
+
+	return msg.ID, nil
+}
+
+// Dequeue leases the oldest visible message for the visibility timeout, ErrEmpty is returned if
+// there is none. the messages that have been delivered MaxAttempts times are dead-lettered instead
+func (q *Queue) Dequeue() (*Message, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	var offset []byte
+
+	for {
+		page, err := q.page(q.messages, offset, scanPage)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(page) < 1 {
+			return nil, ErrEmpty
+		}
+
+		leased, err := q.leasedIDs(page[0].ID, page[len(page)-1].ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, msg := range page {
+			if leased[msg.ID] {
+				continue
+			}
+
+			if msg.Attempts >= q.opts.MaxAttempts {
+				if err := q.deadLetter(msg); err != nil {
+					return nil, err
+				}
+
+				continue
+			}
+
+			msg.Attempts++
+
+			err = q.provider.Batch([]*goukv.Entry{
+				{Key: q.key(q.messages, msg.ID), Value: encode(msg)},
+				{Key: q.key(q.leases, msg.ID), Value: []byte("1"), TTL: q.opts.VisibilityTimeout},
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return msg, nil
+		}
+
+		if len(page) < scanPage {
+			return nil, ErrEmpty
+		}
+
+		offset = q.key(q.messages, page[len(page)-1].ID)
+	}
+}
+
+// DequeueWait blocks until a message is dequeued or the specified context is done, it is woken up
+// by the changes of the queue if the provider (or the provider it wraps) implements goukv.Watcher,
+// otherwise it polls the queue
+func (q *Queue) DequeueWait(ctx context.Context) (*Message, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// an unsupported or failed watch falls back to polling
+	changes, _ := goukv.Watch(ctx, q.provider, q.messages)
+
+	ticker := time.NewTicker(q.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		msg, err := q.Dequeue()
+		if err != ErrEmpty {
+			return msg, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case _, ok := <-changes:
+			if !ok {
+				changes = nil
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// Ack removes the specified dequeued message, ErrLeaseLost is returned if it has been
+// delivered again after its visibility timeout or removed
+func (q *Queue) Ack(msg *Message) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if err := q.verify(msg); err != nil {
+		return err
+	}
+
+	return q.provider.Batch([]*goukv.Entry{
+		{Key: q.key(q.messages, msg.ID)},
+		{Key: q.key(q.leases, msg.ID)},
+	})
+}
+
+// Nack returns the specified dequeued message to the queue, it becomes visible again after the
+// specified delay, or it is dead-lettered if it has been delivered MaxAttempts times
+func (q *Queue) Nack(msg *Message, delay time.Duration) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if err := q.verify(msg); err != nil {
+		return err
+	}
+
+	if msg.Attempts >= q.opts.MaxAttempts {
+		return q.deadLetter(msg)
+	}
+
+	if delay > 0 {
+		return q.provider.Put(&goukv.Entry{Key: q.key(q.leases, msg.ID), Value: []byte("1"), TTL: delay})
+	}
+
+	return q.provider.Delete(q.key(q.leases, msg.ID))
+}
+
+// DeadLetters returns the dead-lettered messages, limit < 1 means no limit
+func (q *Queue) DeadLetters(limit int) ([]*Message, error) {
+	return q.page(q.dead, nil, limit)
+}
+
+// verify whether the specified message is still the last delivery of its id
+func (q *Queue) verify(msg *Message) error {
+	current, err := q.get(msg.ID)
+	if err == goukv.ErrKeyNotFound {
+		return ErrLeaseLost
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if current.Attempts != msg.Attempts {
+		return ErrLeaseLost
+	}
+
+	return nil
+}
+
+// deadLetter moves the specified message to the dead letters
+func (q *Queue) deadLetter(msg *Message) error {
+	return q.provider.Batch([]*goukv.Entry{
+		{Key: q.key(q.dead, msg.ID), Value: encode(msg)},
+		{Key: q.key(q.messages, msg.ID)},
+		{Key: q.key(q.leases, msg.ID)},
+	})
+}
+
+// leasedIDs returns the ids between the specified ones (inclusive) whose messages have a live lease,
+// the leases of a page of messages are fetched using a single scan instead of a lookup per message
+func (q *Queue) leasedIDs(first, last string) (map[string]bool, error) {
+	result := map[string]bool{}
+
+	err := q.provider.Scan(goukv.ScanOpts{
+		Prefix:        q.leases,
+		Offset:        q.key(q.leases, first),
+		IncludeOffset: true,
+		Scanner: func(k, v []byte) bool {
+			id := string(k[len(q.leases):])
+			if id > last {
+				return false
+			}
+
+			result[id] = true
+
+			return true
+		},
+	})
+
+	return result, err
+}
+
+// get fetches the message of the specified id
+func (q *Queue) get(id string) (*Message, error) {
+	val, err := q.provider.Get(q.key(q.messages, id))
+	if err != nil {
+		return nil, err
+	}
+
+	if val == nil {
+		return nil, goukv.ErrKeyNotFound
+	}
+
+	return decode(id, val)
+}
+
+// page returns the messages under the specified prefix that come after the specified offset,
+// the provider isn't accessed while scanning, as some providers don't allow nested operations
+func (q *Queue) page(prefix, offset []byte, limit int) ([]*Message, error) {
+	result := []*Message{}
+
+	var decodeErr error
+
+	err := q.provider.Scan(goukv.ScanOpts{
+		Prefix: prefix,
+		Offset: offset,
+		Scanner: func(k, v []byte) bool {
+			msg, err := decode(string(k[len(prefix):]), v)
+			if err != nil {
+				decodeErr = err
+				return false
+			}
+
+			result = append(result, msg)
+
+			return limit < 1 || len(result) < limit
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, decodeErr
+}
+
+// key returns the key of the specified id under the specified prefix
+func (q *Queue) key(prefix []byte, id string) []byte {
+	key := make([]byte, 0, len(prefix)+len(id))
+	key = append(key, prefix...)
+
+	return append(key, id...)
+}
+
+// nextID returns the next message id, it is the next sequence followed by a random suffix
+func nextID() (string, error) {
+	suffix := make([]byte, idSuffixLength)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+
+	return nextSequence() + "-" + hex.EncodeToString(suffix), nil
+}
+
+// nextSequence returns the next sequence, it is the current time in nanoseconds unless
+// it was already issued, so the sequences issued by the process are increasing
+func nextSequence() string {
+	sequence.Lock()
+	defer sequence.Unlock()
+
+	next := time.Now().UnixNano()
+	if next <= sequence.last {
+		next = sequence.last + 1
+	}
+
+	sequence.last = next
+
+	return fmt.Sprintf("%020d", next)
+}
+
+// encode returns the stored form of the specified message
+func encode(msg *Message) []byte {
+	data, _ := json.Marshal(msg)
+
+	return data
+}
+
+// decode parses the stored form of the message of the specified id
+func decode(id string, data []byte) (*Message, error) {
+	msg := &Message{}
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+
+	msg.ID = id
+
+	return msg, nil
+}
@@ -0,0 +1,131 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/internal/testutil"
+)
+
+func TestQueue(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		q, err := New(db, "jobs", Opts{VisibilityTimeout: 500 * time.Millisecond, MaxAttempts: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, body := range []string{"a", "b", "c"} {
+			if _, err := q.Enqueue([]byte(body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		a, _ := q.Dequeue()
+		b, _ := q.Dequeue()
+		if a == nil || b == nil || string(a.Body) != "a" || string(b.Body) != "b" || a.Attempts != 1 {
+			t.Fatalf("unexpected messages (%v, %v)", a, b)
+		}
+
+		if err := q.Ack(a); err != nil {
+			t.Error(err)
+		}
+
+		if err := q.Nack(b, 0); err != nil {
+			t.Error(err)
+		}
+
+		// b is visible again before c
+		if msg, err := q.Dequeue(); err != nil || string(msg.Body) != "b" || msg.Attempts != 2 {
+			t.Errorf("expected (b) to be delivered again, found (%v, %v)", msg, err)
+		}
+
+		if msg, err := q.Dequeue(); err != nil || string(msg.Body) != "c" {
+			t.Errorf("expected (c), found (%v, %v)", msg, err)
+		}
+
+		if _, err := q.Dequeue(); err != ErrEmpty {
+			t.Errorf("expected (%v), found (%v)", ErrEmpty, err)
+		}
+
+		// the leases expire, b has been delivered twice so it is dead-lettered
+		var c *Message
+		testutil.Eventually(t, 5*time.Second, func() bool {
+			c, err = q.Dequeue()
+			return err != ErrEmpty
+		})
+
+		if err != nil || string(c.Body) != "c" || c.Attempts != 2 {
+			t.Errorf("expected (c) to be delivered again, found (%v, %v)", c, err)
+		}
+
+		dead, err := q.DeadLetters(0)
+		if err != nil || len(dead) != 1 || string(dead[0].Body) != "b" {
+			t.Errorf("expected (b) to be dead-lettered, found (%v, %v)", dead, err)
+		}
+
+		if err := q.Ack(b); err != ErrLeaseLost {
+			t.Errorf("expected (%v), found (%v)", ErrLeaseLost, err)
+		}
+	})
+}
+
+func TestDequeueWait(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		q, _ := New(db, "jobs", Opts{PollInterval: 10 * time.Millisecond})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+
+		if _, err := q.DequeueWait(ctx); err != context.DeadlineExceeded {
+			t.Errorf("expected (%v), found (%v)", context.DeadlineExceeded, err)
+		}
+
+		go q.Enqueue([]byte("a"))
+
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		msg, err := q.DequeueWait(ctx)
+		if err != nil || string(msg.Body) != "a" {
+			t.Errorf("expected (a), found (%v, %v)", msg, err)
+		}
+	})
+}
+
+func TestConcurrentProcesses(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		q, err := New(db, "jobs", Opts{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// two processes enqueueing at the same nanosecond issue the same sequence
+		future := time.Now().Add(time.Hour).UnixNano()
+		ids := map[string]bool{}
+
+		for _, body := range []string{"a", "b"} {
+			sequence.Lock()
+			sequence.last = future
+			sequence.Unlock()
+
+			id, err := q.Enqueue([]byte(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ids[id] = true
+		}
+
+		if len(ids) != 2 {
+			t.Fatalf("expected (2) distinct ids, found %v", ids)
+		}
+
+		for i := 0; i < 2; i++ {
+			if _, err := q.Dequeue(); err != nil {
+				t.Errorf("expected both messages to be kept, found (%v)", err)
+			}
+		}
+	})
+}
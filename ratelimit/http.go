@@ -0,0 +1,48 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// KeyFunc returns the rate limiting key of the specified request
+type KeyFunc func(*http.Request) string
+
+// ByRemoteIP limits the requests by the IP of the client, it doesn't trust the forwarding headers
+func ByRemoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// Middleware limits the requests of the wrapped handler by the keys returned by the specified function,
+// the rejected requests are answered by `429 Too Many Requests` and a `Retry-After` header, and the
+// `X-RateLimit-Limit` and `X-RateLimit-Remaining` headers are set for all the requests.
+// the requests are answered by `500 Internal Server Error` if the limiter fails
+func Middleware(l *Limiter, key KeyFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := l.Allow(r.Context(), key(r))
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
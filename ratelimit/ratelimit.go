@@ -0,0 +1,244 @@
+// Package ratelimit implements rate limiters shared through goukv providers that support conditional writes.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alash3al/goukv"
+)
+
+const (
+	keyPrefix = "ratelimit:"
+)
+
+// error related variables
+var (
+	ErrCompareAndSwapUnsupported = goukv.ErrCompareAndSwapUnsupported
+	ErrInvalidLimit              = errors.New("the limit, the window and the rate must be positive")
+)
+
+// Result the decision of a limiter
+type Result struct {
+	// Allowed whether the request is allowed
+	Allowed bool
+
+	// Limit the maximum number of requests of the window, or the bucket size
+	Limit int
+
+	// Remaining how many requests could still be allowed right now
+	Remaining int
+
+	// RetryAfter how long to wait before the next request could be allowed, if it isn't allowed
+	RetryAfter time.Duration
+}
+
+// algorithm computes the next state of a key from its current one, a nil state means that
+// the key has no state, and a nil next state means that the state doesn't change
+type algorithm interface {
+	name() string
+	apply(state []byte, now time.Time) (next []byte, ttl time.Duration, result Result)
+}
+
+// Limiter limits the requests per key, the state of each key is a single entry named
+// `ratelimit:<algorithm>:<url escaped key>` that is updated using compare-and-swap,
+// so the limit is shared by all the processes using the same provider
+type Limiter struct {
+	provider  goukv.Provider
+	algorithm algorithm
+
+	// now returns the current time, it is replaced by the tests
+	now func() time.Time
+}
+
+// NewFixedWindow returns a limiter that allows the specified number of requests per key
+// in each fixed window of the specified duration
+func NewFixedWindow(p goukv.Provider, limit int, window time.Duration) (*Limiter, error) {
+	if limit < 1 || window <= 0 {
+		return nil, ErrInvalidLimit
+	}
+
+	return newLimiter(p, fixedWindow{max: limit, window: window})
+}
+
+// NewSlidingLog returns a limiter that allows the specified number of requests per key within
+// any window of the specified duration, it keeps the time of each allowed request of the window
+func NewSlidingLog(p goukv.Provider, limit int, window time.Duration) (*Limiter, error) {
+	if limit < 1 || window <= 0 {
+		return nil, ErrInvalidLimit
+	}
+
+	return newLimiter(p, slidingLog{max: limit, window: window})
+}
+
+// NewTokenBucket returns a limiter whose buckets hold the specified number of tokens and are refilled
+// by the specified number of tokens per second, each allowed request takes a token
+func NewTokenBucket(p goukv.Provider, rate float64, burst int) (*Limiter, error) {
+	if burst < 1 || rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return nil, ErrInvalidLimit
+	}
+
+	return newLimiter(p, tokenBucket{rate: rate, burst: burst})
+}
+
+// newLimiter initializes a new limiter using the specified algorithm
+func newLimiter(p goukv.Provider, algo algorithm) (*Limiter, error) {
+	if !goukv.SupportsCompareAndSwap(p) {
+		return nil, ErrCompareAndSwapUnsupported
+	}
+
+	return &Limiter{
+		provider:  p,
+		algorithm: algo,
+		now:       time.Now,
+	}, nil
+}
+
+// Allow decides whether a request of the specified key is allowed and records it if so,
+// the specified context is bound to the provider (see goukv.WithContext)
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	p := goukv.WithContext(ctx, l.provider)
+
+	k := []byte(keyPrefix + l.algorithm.name() + ":" + url.QueryEscape(key))
+
+	// the state is read then swapped, so a concurrent update makes the swap fail and the decision is retried
+	for {
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+
+		state, err := p.Get(k)
+		if err == goukv.ErrKeyNotFound || err == goukv.ErrKeyExpired {
+			state, err = nil, nil
+		}
+
+		if err != nil {
+			return Result{}, err
+		}
+
+		next, ttl, result := l.algorithm.apply(state, l.now())
+		if next == nil {
+			return result, nil
+		}
+
+		swapped, err := goukv.CompareAndSwap(p, &goukv.Entry{Key: k, Value: next, TTL: stateTTL(ttl)}, state)
+		if err != nil {
+			return Result{}, err
+		}
+
+		if swapped {
+			return result, nil
+		}
+	}
+}
+
+// fixedWindow the state is `<window start> <count>`
+type fixedWindow struct {
+	max    int
+	window time.Duration
+}
+
+func (a fixedWindow) name() string {
+	return fmt.Sprintf("fw:%d:%s", a.max, a.window)
+}
+
+func (a fixedWindow) apply(state []byte, now time.Time) ([]byte, time.Duration, Result) {
+	start := now.Truncate(a.window)
+	count := 0
+
+	if fields := strings.Fields(string(state)); len(fields) == 2 && fields[0] == strconv.FormatInt(start.UnixNano(), 10) {
+		count, _ = strconv.Atoi(fields[1])
+	}
+
+	end := start.Add(a.window)
+
+	if count >= a.max {
+		return nil, 0, Result{Limit: a.max, RetryAfter: end.Sub(now)}
+	}
+
+	count++
+	next := []byte(strconv.FormatInt(start.UnixNano(), 10) + " " + strconv.Itoa(count))
+
+	return next, end.Sub(now), Result{Allowed: true, Limit: a.max, Remaining: a.max - count}
+}
+
+// slidingLog the state is the space separated times of the allowed requests of the window
+type slidingLog struct {
+	max    int
+	window time.Duration
+}
+
+func (a slidingLog) name() string {
+	return fmt.Sprintf("sl:%d:%s", a.max, a.window)
+}
+
+func (a slidingLog) apply(state []byte, now time.Time) ([]byte, time.Duration, Result) {
+	since := now.Add(-a.window).UnixNano()
+	log := []string{}
+
+	for _, field := range strings.Fields(string(state)) {
+		if t, err := strconv.ParseInt(field, 10, 64); err == nil && t > since {
+			log = append(log, field)
+		}
+	}
+
+	if len(log) >= a.max {
+		oldest, _ := strconv.ParseInt(log[0], 10, 64)
+		return nil, 0, Result{Limit: a.max, RetryAfter: time.Duration(oldest - since)}
+	}
+
+	log = append(log, strconv.FormatInt(now.UnixNano(), 10))
+
+	return []byte(strings.Join(log, " ")), a.window, Result{Allowed: true, Limit: a.max, Remaining: a.max - len(log)}
+}
+
+// tokenBucket the state is `<tokens> <last refill time>`, a missing state is a full bucket
+type tokenBucket struct {
+	rate  float64
+	burst int
+}
+
+func (a tokenBucket) name() string {
+	return fmt.Sprintf("tb:%g:%d", a.rate, a.burst)
+}
+
+func (a tokenBucket) apply(state []byte, now time.Time) ([]byte, time.Duration, Result) {
+	tokens := float64(a.burst)
+
+	if fields := strings.Fields(string(state)); len(fields) == 2 {
+		left, err1 := strconv.ParseFloat(fields[0], 64)
+		last, err2 := strconv.ParseInt(fields[1], 10, 64)
+
+		if err1 == nil && err2 == nil {
+			elapsed := now.Sub(time.Unix(0, last)).Seconds()
+			tokens = math.Min(float64(a.burst), left+math.Max(elapsed, 0)*a.rate)
+		}
+	}
+
+	if tokens < 1 {
+		return nil, 0, Result{Limit: a.burst, RetryAfter: seconds((1 - tokens) / a.rate)}
+	}
+
+	tokens--
+	next := []byte(strconv.FormatFloat(tokens, 'g', -1, 64) + " " + strconv.FormatInt(now.UnixNano(), 10))
+
+	// the bucket is full again once the state expires
+	return next, seconds((float64(a.burst) - tokens) / a.rate), Result{Allowed: true, Limit: a.burst, Remaining: int(tokens)}
+}
+
+// stateTTL rounds the specified ttl up to the next second, as some providers store the expiration
+// times in seconds, the states carry their own times, so keeping them longer doesn't change the decisions
+func stateTTL(ttl time.Duration) time.Duration {
+	return ttl.Truncate(time.Second) + time.Second
+}
+
+// seconds converts the specified seconds to a duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
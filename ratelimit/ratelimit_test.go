@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/internal/testutil"
+)
+
+// allowed returns how many of the specified number of requests are allowed
+func allowed(t *testing.T, l *Limiter, key string, n int) int {
+	count := 0
+
+	for i := 0; i < n; i++ {
+		result, err := l.Allow(context.Background(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result.Allowed {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestAlgorithms(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		fixed, _ := NewFixedWindow(db, 3, time.Hour)
+		sliding, _ := NewSlidingLog(db, 3, 50*time.Millisecond)
+		bucket, _ := NewTokenBucket(db, 20, 3)
+
+		clock := testutil.NewClock()
+		for _, l := range []*Limiter{fixed, sliding, bucket} {
+			l.now = clock.Now
+		}
+
+		for name, l := range map[string]*Limiter{"fixed": fixed, "sliding": sliding, "bucket": bucket} {
+			if n := allowed(t, l, "a", 5); n != 3 {
+				t.Errorf("%s: expected (3) allowed requests, found (%d)", name, n)
+			}
+
+			if n := allowed(t, l, "b", 1); n != 1 {
+				t.Errorf("%s: expected the keys to be limited separately, found (%d)", name, n)
+			}
+		}
+
+		result, _ := fixed.Allow(context.Background(), "a")
+		if result.Allowed || result.Limit != 3 || result.RetryAfter <= 0 {
+			t.Errorf("unexpected result (%+v)", result)
+		}
+
+		clock.Advance(60 * time.Millisecond)
+
+		if n := allowed(t, sliding, "a", 5); n != 3 {
+			t.Errorf("expected the sliding window to move, found (%d)", n)
+		}
+
+		// a token is refilled each 50ms
+		if n := allowed(t, bucket, "a", 5); n != 1 {
+			t.Errorf("expected the bucket to be refilled by a token, found (%d)", n)
+		}
+	})
+}
+
+func TestConcurrentAllow(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		l, _ := NewFixedWindow(db, 50, time.Hour)
+
+		var wg sync.WaitGroup
+		var lock sync.Mutex
+		count := 0
+
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				n := allowed(t, l, "k", 10)
+
+				lock.Lock()
+				count += n
+				lock.Unlock()
+			}()
+		}
+
+		wg.Wait()
+
+		if count != 50 {
+			t.Errorf("expected (50) allowed requests, found (%d)", count)
+		}
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		l, _ := NewFixedWindow(db, 1, time.Hour)
+		handler := Middleware(l, ByRemoteIP)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		for i, expected := range []int{http.StatusOK, http.StatusTooManyRequests} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != expected {
+				t.Errorf("request (%d): expected (%d), found (%d)", i, expected, rec.Code)
+			}
+		}
+	})
+}
+
+// unsupported a provider that doesn't implement goukv.CompareAndSwapper
+type unsupported struct {
+	goukv.Provider
+}
+
+func TestUnsupportedProvider(t *testing.T) {
+	if _, err := NewTokenBucket(unsupported{}, 1, 1); err != ErrCompareAndSwapUnsupported {
+		t.Errorf("expected (%v), found (%v)", ErrCompareAndSwapUnsupported, err)
+	}
+
+	// the wrappers implement goukv.CompareAndSwapper but the wrapped provider doesn't
+	if _, err := NewTokenBucket(goukv.Namespace(unsupported{}, "ns"), 1, 1); err != ErrCompareAndSwapUnsupported {
+		t.Errorf("expected (%v), found (%v)", ErrCompareAndSwapUnsupported, err)
+	}
+}
+
+func TestWrappedProvider(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		l, err := NewFixedWindow(goukv.Namespace(goukv.NewCached(db, goukv.CacheOpts{}), "ns"), 2, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n := allowed(t, l, "k", 3); n != 2 {
+			t.Errorf("expected (2) allowed requests, found (%d)", n)
+		}
+	})
+}
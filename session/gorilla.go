@@ -0,0 +1,113 @@
+package session
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+)
+
+// GorillaStore implements gorilla's sessions.Store on top of a session store, the values are stored
+// using the store codec and the cookies only hold the session ids signed by the specified key pairs,
+// the same way gorilla's FilesystemStore does
+type GorillaStore struct {
+	Codecs  []securecookie.Codec
+	Options *sessions.Options
+
+	store *Store
+}
+
+// NewGorillaStore returns a gorilla sessions store backed by the specified store, the key pairs
+// are passed to securecookie.CodecsFromPairs, see gorilla's sessions.NewCookieStore
+func NewGorillaStore(s *Store, keyPairs ...[]byte) *GorillaStore {
+	return &GorillaStore{
+		Codecs: securecookie.CodecsFromPairs(keyPairs...),
+		Options: &sessions.Options{
+			Path:     "/",
+			MaxAge:   int(s.opts.TTL.Seconds()),
+			HttpOnly: true,
+		},
+		store: s,
+	}
+}
+
+// Get implements sessions.Store, it returns the cached session of the request if any
+func (g *GorillaStore) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(g, name)
+}
+
+// New implements sessions.Store, it loads the session of the request cookie,
+// a new session is returned if there is none
+func (g *GorillaStore) New(r *http.Request, name string) (*sessions.Session, error) {
+	sess := sessions.NewSession(g, name)
+	opts := *g.Options
+	sess.Options = &opts
+	sess.IsNew = true
+
+	c, err := r.Cookie(name)
+	if err != nil {
+		return sess, nil
+	}
+
+	if err := securecookie.DecodeMulti(name, c.Value, &sess.ID, g.Codecs...); err != nil {
+		return sess, err
+	}
+
+	err = g.store.load(sess.ID, &sess.Values)
+	if err == ErrNotFound {
+		sess.ID = ""
+		return sess, nil
+	}
+
+	if err != nil {
+		return sess, err
+	}
+
+	sess.IsNew = false
+
+	return sess, nil
+}
+
+// Save implements sessions.Store, the session is deleted if its MaxAge is negative,
+// otherwise it expires after its MaxAge or after the store TTL if it is 0
+func (g *GorillaStore) Save(r *http.Request, w http.ResponseWriter, sess *sessions.Session) error {
+	if sess.Options.MaxAge < 0 {
+		if sess.ID != "" {
+			if err := g.store.Destroy(sess.ID); err != nil {
+				return err
+			}
+		}
+
+		http.SetCookie(w, sessions.NewCookie(sess.Name(), "", sess.Options))
+
+		return nil
+	}
+
+	if sess.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+
+		sess.ID = id
+	}
+
+	ttl := g.store.opts.TTL
+	if sess.Options.MaxAge > 0 {
+		ttl = time.Duration(sess.Options.MaxAge) * time.Second
+	}
+
+	if err := g.store.save(sess.ID, &sess.Values, ttl); err != nil {
+		return err
+	}
+
+	encoded, err := securecookie.EncodeMulti(sess.Name(), sess.ID, g.Codecs...)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, sessions.NewCookie(sess.Name(), encoded, sess.Options))
+
+	return nil
+}
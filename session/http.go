@@ -0,0 +1,147 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+const (
+	defaultCookieName = "session"
+)
+
+// contextKey the key of the request session in the request context
+type contextKey struct{}
+
+// FromContext returns the session attached to the specified context by Store.Middleware, if any
+func FromContext(ctx context.Context) *Session {
+	sess, _ := ctx.Value(contextKey{}).(*Session)
+
+	return sess
+}
+
+// Middleware attaches the session of the request (see FromContext) to the request context, a new session
+// is created if the request has none. the session is saved right before the response headers are written
+// if it has been modified, regenerated or extended (the sliding expiration), so its cookie is sent along.
+// the specified cookie is used as a template, its name and path are `session` and `/` by default,
+// and it is always HttpOnly
+func (s *Store) Middleware(cookie http.Cookie) func(http.Handler) http.Handler {
+	if cookie.Name == "" {
+		cookie.Name = defaultCookieName
+	}
+
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+
+	cookie.HttpOnly = true
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sess, err := s.fromRequest(r, cookie.Name)
+			if err != nil {
+				s.handleError(r, err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			rw := &responseWriter{
+				ResponseWriter: w,
+				commit: func() {
+					if err := s.commit(w, sess, cookie); err != nil {
+						s.handleError(r, err)
+					}
+				},
+			}
+
+			next.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), contextKey{}, sess)))
+
+			rw.once.Do(rw.commit)
+		})
+	}
+}
+
+// fromRequest loads the session of the specified request, a new one is created if it has none
+// or if its session doesn't exist anymore
+func (s *Store) fromRequest(r *http.Request, name string) (*Session, error) {
+	c, err := r.Cookie(name)
+	if err != nil {
+		return s.Create()
+	}
+
+	sess, err := s.Load(c.Value)
+	if err == ErrNotFound {
+		return s.Create()
+	}
+
+	return sess, err
+}
+
+// commit stores the changes of the specified session and sends its cookie
+func (s *Store) commit(w http.ResponseWriter, sess *Session, cookie http.Cookie) error {
+	sess.mu.Lock()
+	destroyed, regenerate, dirty := sess.destroyed, sess.regenerate, sess.dirty
+	sess.regenerate = false
+	sess.mu.Unlock()
+
+	switch {
+	case destroyed:
+		cookie.MaxAge = -1
+		http.SetCookie(w, &cookie)
+
+		if sess.IsNew {
+			return nil
+		}
+
+		return s.Destroy(sess.ID)
+	case regenerate && !sess.IsNew:
+		if err := s.Regenerate(sess); err != nil {
+			return err
+		}
+	case regenerate, dirty:
+		if err := s.Save(sess); err != nil {
+			return err
+		}
+	case s.opts.Sliding && !sess.IsNew:
+		// the session has been extended once it was loaded, so its cookie is extended too
+	default:
+		return nil
+	}
+
+	cookie.Value = sess.ID
+	cookie.MaxAge = int(s.opts.TTL.Seconds())
+	http.SetCookie(w, &cookie)
+
+	return nil
+}
+
+// handleError passes the specified error to the error handler if any
+func (s *Store) handleError(r *http.Request, err error) {
+	if s.opts.ErrorHandler != nil {
+		s.opts.ErrorHandler(r, err)
+	}
+}
+
+// responseWriter commits the session right before the response headers are written
+type responseWriter struct {
+	http.ResponseWriter
+	commit func()
+	once   sync.Once
+}
+
+// WriteHeader implements http.ResponseWriter
+func (w *responseWriter) WriteHeader(code int) {
+	w.once.Do(w.commit)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write implements http.ResponseWriter
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.once.Do(w.commit)
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the original response writer, so http.ResponseController could reach it
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
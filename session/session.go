@@ -0,0 +1,250 @@
+// Package session stores web sessions in goukv providers.
+package session
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/alash3al/goukv"
+)
+
+const (
+	keyPrefix = "session:"
+	idLength  = 32
+
+	defaultTTL = 24 * time.Hour
+)
+
+// error related variables
+var (
+	ErrNotFound = errors.New("the session doesn't exist or has expired")
+)
+
+// Opts the options of a session store
+type Opts struct {
+	// TTL how long a session is kept since it was saved, 24 hours by default
+	TTL time.Duration
+
+	// Sliding extends the sessions by their TTL whenever they are loaded
+	Sliding bool
+
+	// Codec the codec of the session values, goukv.GobCodec by default, the values of the
+	// gorilla sessions are `map[interface{}]interface{}` so they can't be encoded using JSON
+	Codec goukv.Codec
+
+	// ErrorHandler is called by the middleware with the errors it can't return,
+	// i.e: a failure to save a session, they are ignored if it is nil
+	ErrorHandler func(*http.Request, error)
+}
+
+// Store stores the sessions values under `session:<id>`, each session expires (Entry.TTL) after the TTL,
+// the ids are 256 bits random strings, so they are stored and sent as is
+type Store struct {
+	provider goukv.Provider
+	opts     Opts
+}
+
+// New initializes a new session store on top of the specified provider
+func New(p goukv.Provider, opts Opts) *Store {
+	if opts.TTL <= 0 {
+		opts.TTL = defaultTTL
+	}
+
+	if opts.Codec == nil {
+		opts.Codec = goukv.GobCodec
+	}
+
+	return &Store{
+		provider: p,
+		opts:     opts,
+	}
+}
+
+// Create returns a new empty session, it isn't stored until it is saved
+func (s *Store) Create() (*Session, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		ID:     id,
+		IsNew:  true,
+		values: map[string]interface{}{},
+	}, nil
+}
+
+// Load fetches the session of the specified id, and extends it if the sliding expiration is enabled
+func (s *Store) Load(id string) (*Session, error) {
+	values := map[string]interface{}{}
+
+	if err := s.load(id, &values); err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		ID:     id,
+		values: values,
+	}, nil
+}
+
+// Save stores the specified session for the TTL
+func (s *Store) Save(sess *Session) error {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if err := s.save(sess.ID, &sess.values, s.opts.TTL); err != nil {
+		return err
+	}
+
+	sess.IsNew, sess.dirty = false, false
+
+	return nil
+}
+
+// Regenerate moves the specified session to a new id and deletes the old one, it should be called
+// whenever the privileges of the session change, i.e: on login, to prevent session fixation
+func (s *Store) Regenerate(sess *Session) error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	// the session keeps its old id unless it is stored using the new one
+	if err := s.save(id, &sess.values, s.opts.TTL); err != nil {
+		return err
+	}
+
+	old := sess.ID
+	sess.ID, sess.IsNew, sess.dirty = id, false, false
+
+	return s.Destroy(old)
+}
+
+// Destroy deletes the session of the specified id
+func (s *Store) Destroy(id string) error {
+	return s.provider.Delete(key(id))
+}
+
+// load decodes the values of the session of the specified id into the specified pointer
+func (s *Store) load(id string, values interface{}) error {
+	if !validID(id) {
+		return ErrNotFound
+	}
+
+	data, err := s.provider.Get(key(id))
+	if err == goukv.ErrKeyNotFound || err == goukv.ErrKeyExpired || (err == nil && data == nil) {
+		return ErrNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.opts.Codec.Unmarshal(data, values); err != nil {
+		return err
+	}
+
+	if s.opts.Sliding {
+		return s.provider.Put(&goukv.Entry{Key: key(id), Value: data, TTL: s.opts.TTL})
+	}
+
+	return nil
+}
+
+// save encodes and stores the specified values of the session of the specified id for the specified ttl
+func (s *Store) save(id string, values interface{}, ttl time.Duration) error {
+	data, err := s.opts.Codec.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	return s.provider.Put(&goukv.Entry{Key: key(id), Value: data, TTL: ttl})
+}
+
+// Session the values of a session, it is safe for concurrent use
+type Session struct {
+	// ID the session id
+	ID string
+
+	// IsNew whether the session hasn't been saved yet
+	IsNew bool
+
+	values     map[string]interface{}
+	dirty      bool
+	regenerate bool
+	destroyed  bool
+
+	mu sync.Mutex
+}
+
+// Get returns the value of the specified key
+func (s *Session) Get(k string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.values[k]
+}
+
+// Set sets the value of the specified key
+func (s *Session) Set(k string, v interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[k] = v
+	s.dirty = true
+}
+
+// Delete deletes the specified key
+func (s *Session) Delete(k string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.values, k)
+	s.dirty = true
+}
+
+// Regenerate moves the session to a new id once the request is handled (see Store.Middleware)
+func (s *Session) Regenerate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.regenerate = true
+}
+
+// Destroy deletes the session once the request is handled (see Store.Middleware)
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.destroyed = true
+}
+
+// key returns the key of the session of the specified id
+func key(id string) []byte {
+	return []byte(keyPrefix + id)
+}
+
+// newID returns a new random session id
+func newID() (string, error) {
+	buf := make([]byte, idLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// validID whether the specified id could have been issued by newID
+func validID(id string) bool {
+	buf, err := base64.RawURLEncoding.DecodeString(id)
+
+	return err == nil && len(buf) == idLength
+}
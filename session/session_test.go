@@ -0,0 +1,169 @@
+package session
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/internal/testutil"
+	"github.com/gorilla/sessions"
+)
+
+func TestStore(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		store := New(db, Opts{TTL: 200 * time.Millisecond, Sliding: true})
+
+		sess, _ := store.Create()
+		sess.Set("user", "someone")
+
+		if err := store.Save(sess); err != nil {
+			t.Fatal(err)
+		}
+
+		saved, err := db.TTL(key(sess.ID))
+		if err != nil || saved == nil {
+			t.Fatalf("expected the session to expire, found (%v, %v)", saved, err)
+		}
+
+		// the sliding expiration extends the session whenever it is accessed
+		testutil.Eventually(t, 5*time.Second, func() bool {
+			loaded, err := store.Load(sess.ID)
+			if err != nil || loaded.Get("user") != "someone" {
+				t.Fatalf("expected (someone), found (%v, %v)", loaded, err)
+			}
+
+			extended, err := db.TTL(key(sess.ID))
+			return err == nil && extended != nil && extended.After(*saved)
+		})
+
+		old := sess.ID
+		if err := store.Regenerate(sess); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := store.Load(old); err != ErrNotFound {
+			t.Errorf("expected (%v), found (%v)", ErrNotFound, err)
+		}
+
+		// loading through a non sliding store doesn't extend the session
+		fixed := New(db, Opts{TTL: 200 * time.Millisecond})
+		testutil.Eventually(t, 5*time.Second, func() bool {
+			_, err := fixed.Load(sess.ID)
+			return err == ErrNotFound
+		})
+
+		if _, err := store.Load("../invalid"); err != ErrNotFound {
+			t.Errorf("expected (%v), found (%v)", ErrNotFound, err)
+		}
+	})
+}
+
+// failingProvider a provider whose writes fail
+type failingProvider struct {
+	goukv.Provider
+}
+
+func (failingProvider) Put(*goukv.Entry) error {
+	return errors.New("write failed")
+}
+
+func TestRegenerateFailure(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		sess, _ := New(db, Opts{}).Create()
+		old := sess.ID
+
+		if err := New(failingProvider{db}, Opts{}).Regenerate(sess); err == nil {
+			t.Fatal("expected the regeneration to fail")
+		}
+
+		if sess.ID != old {
+			t.Errorf("expected the session to keep its id (%s), found (%s)", old, sess.ID)
+		}
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		store := New(db, Opts{})
+		handler := store.Middleware(http.Cookie{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sess := FromContext(r.Context())
+
+			switch r.URL.Path {
+			case "/login":
+				sess.Set("user", "someone")
+				sess.Regenerate()
+			case "/logout":
+				sess.Destroy()
+			}
+
+			if user, _ := sess.Get("user").(string); user != "" {
+				w.Write([]byte(user))
+			}
+		}))
+
+		serve := func(path string, cookie *http.Cookie) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			if cookie != nil {
+				req.AddCookie(cookie)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			return rec
+		}
+
+		if rec := serve("/", nil); len(rec.Result().Cookies()) != 0 {
+			t.Error("expected the unmodified new session not to be saved")
+		}
+
+		rec := serve("/login", nil)
+		cookies := rec.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Name != defaultCookieName || !cookies[0].HttpOnly {
+			t.Fatalf("unexpected cookies (%v)", cookies)
+		}
+
+		if rec := serve("/", cookies[0]); rec.Body.String() != "someone" {
+			t.Errorf("expected (someone), found (%s)", rec.Body.String())
+		}
+
+		rec = serve("/logout", cookies[0])
+		if logout := rec.Result().Cookies(); len(logout) != 1 || logout[0].MaxAge >= 0 {
+			t.Errorf("expected the cookie to be deleted, found (%v)", logout)
+		}
+
+		if rec := serve("/", cookies[0]); rec.Body.String() != "" {
+			t.Errorf("expected the session to be destroyed, found (%s)", rec.Body.String())
+		}
+	})
+}
+
+func TestGorillaStore(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		store := NewGorillaStore(New(db, Opts{}), []byte("0123456789abcdef0123456789abcdef"))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+
+		sess, err := store.Get(req, "app")
+		if err != nil || !sess.IsNew {
+			t.Fatalf("expected a new session, found (%v, %v)", sess, err)
+		}
+
+		sess.Values["user"] = "someone"
+		if err := sessions.Save(req, rec); err != nil {
+			t.Fatal(err)
+		}
+
+		req = httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(rec.Result().Cookies()[0])
+
+		sess, err = store.Get(req, "app")
+		if err != nil || sess.IsNew || sess.Values["user"] != "someone" {
+			t.Errorf("expected the stored session, found (%v, %v)", sess, err)
+		}
+	})
+}
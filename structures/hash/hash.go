@@ -0,0 +1,141 @@
+// Package hash implements redis-like hashes (field maps) on top of goukv providers.
+// the increments are serialized by locks that are local to the process, they give no
+// protection against the other processes sharing the same store.
+package hash
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/internal/keylock"
+)
+
+const (
+	keyPrefix = "hash:"
+)
+
+// error related variables
+var (
+	ErrNotInteger = errors.New("the field value isn't an integer")
+)
+
+// Hash a field map stored as one entry per field, the fields of the hash `key` are stored
+// under `hash:<url escaped key>:<field>`, so GetAll is a single prefix scan
+type Hash struct {
+	provider goukv.Provider
+	prefix   []byte
+}
+
+// New returns the hash of the specified key
+func New(p goukv.Provider, key []byte) *Hash {
+	return &Hash{
+		provider: p,
+		prefix:   []byte(keyPrefix + url.QueryEscape(string(key)) + ":"),
+	}
+}
+
+// Set sets the value of the specified field (HSET)
+func (h *Hash) Set(field, value []byte) error {
+	if value == nil {
+		value = []byte{}
+	}
+
+	return h.provider.Put(&goukv.Entry{Key: h.key(field), Value: value})
+}
+
+// SetMany sets the values of the specified fields using a single batch (HSET with multiple fields)
+func (h *Hash) SetMany(fields map[string][]byte) error {
+	entries := make([]*goukv.Entry, 0, len(fields))
+
+	for field, value := range fields {
+		if value == nil {
+			value = []byte{}
+		}
+
+		entries = append(entries, &goukv.Entry{Key: h.key([]byte(field)), Value: value})
+	}
+
+	return h.provider.Batch(entries)
+}
+
+// Get returns the value of the specified field (HGET)
+func (h *Hash) Get(field []byte) ([]byte, error) {
+	val, err := h.provider.Get(h.key(field))
+	if err != nil {
+		return nil, err
+	}
+
+	if val == nil {
+		return nil, goukv.ErrKeyNotFound
+	}
+
+	return val, nil
+}
+
+// Del deletes the specified fields using a single batch (HDEL)
+func (h *Hash) Del(fields ...[]byte) error {
+	entries := make([]*goukv.Entry, 0, len(fields))
+
+	for _, field := range fields {
+		entries = append(entries, &goukv.Entry{Key: h.key(field)})
+	}
+
+	return h.provider.Batch(entries)
+}
+
+// GetAll returns all the fields and their values (HGETALL)
+func (h *Hash) GetAll() (map[string][]byte, error) {
+	fields := map[string][]byte{}
+
+	err := h.provider.Scan(goukv.ScanOpts{
+		Prefix: h.prefix,
+		Scanner: func(k, v []byte) bool {
+			fields[string(k[len(h.prefix):])] = append([]byte{}, v...)
+			return true
+		},
+	})
+
+	return fields, err
+}
+
+// IncrBy increments the integer value of the specified field by the specified delta and returns the
+// new value (HINCRBY), a missing field is considered 0. the increments are serialized within the
+// process only, concurrent increments of the same field by other processes may be lost
+func (h *Hash) IncrBy(field []byte, delta int64) (int64, error) {
+	key := h.key(field)
+
+	lock := keylock.Of(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	var current int64
+
+	val, err := h.Get(field)
+	switch err {
+	case nil:
+		if current, err = strconv.ParseInt(string(val), 10, 64); err != nil {
+			return 0, ErrNotInteger
+		}
+	case goukv.ErrKeyNotFound, goukv.ErrKeyExpired:
+	default:
+		return 0, err
+	}
+
+	current += delta
+
+	if err := h.provider.Put(&goukv.Entry{Key: key, Value: []byte(strconv.FormatInt(current, 10))}); err != nil {
+		return 0, err
+	}
+
+	return current, nil
+}
+
+// key returns the entry key of the specified field
+func (h *Hash) key(field []byte) []byte {
+	key := make([]byte, 0, len(h.prefix)+len(field))
+	key = append(key, h.prefix...)
+
+	return append(key, field...)
+}
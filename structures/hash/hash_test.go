@@ -0,0 +1,60 @@
+package hash
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/internal/testutil"
+)
+
+func TestHash(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		profile := New(db, []byte("user:1"))
+		other := New(db, []byte("user:10"))
+
+		profile.Set([]byte("name"), []byte("someone"))
+		profile.SetMany(map[string][]byte{"email": []byte("someone@example.com"), "country": []byte("eg")})
+		other.Set([]byte("name"), []byte("other"))
+
+		if val, err := profile.Get([]byte("name")); err != nil || string(val) != "someone" {
+			t.Errorf("expected (someone), found (%s, %v)", string(val), err)
+		}
+
+		profile.Del([]byte("country"))
+
+		fields, err := profile.GetAll()
+		if err != nil || len(fields) != 2 || string(fields["email"]) != "someone@example.com" {
+			t.Errorf("unexpected fields (%v, %v)", fields, err)
+		}
+
+		if _, err := profile.Get([]byte("country")); err != goukv.ErrKeyNotFound {
+			t.Errorf("expected (%v), found (%v)", goukv.ErrKeyNotFound, err)
+		}
+	})
+}
+
+func TestIncrBy(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		counters := New(db, []byte("counters"))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go (func() {
+				defer wg.Done()
+				counters.IncrBy([]byte("visits"), 2)
+			})()
+		}
+		wg.Wait()
+
+		if val, err := counters.IncrBy([]byte("visits"), -1); err != nil || val != 99 {
+			t.Errorf("expected (99), found (%d, %v)", val, err)
+		}
+
+		counters.Set([]byte("name"), []byte("x"))
+		if _, err := counters.IncrBy([]byte("name"), 1); err != ErrNotInteger {
+			t.Errorf("expected (%v), found (%v)", ErrNotInteger, err)
+		}
+	})
+}
@@ -0,0 +1,298 @@
+// Package zset implements redis-like sorted sets on top of goukv providers whose scans are ordered.
+// the updates are serialized by locks that are local to the process, they give no
+// protection against the other processes sharing the same store.
+package zset
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net/url"
+	"strconv"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/internal/keylock"
+)
+
+const (
+	keyPrefix = "zset:"
+
+	// scoreLength the length of the hex encoded scores
+	scoreLength = 16
+)
+
+// error related variables
+var (
+	ErrUnorderedProvider = errors.New("the provider doesn't support ordered scans, see goukv.OrderedScanner")
+	ErrInvalidScore      = errors.New("the score must be a number")
+	ErrMalformedEntry    = errors.New("the sorted set entry is malformed")
+)
+
+// Member a member of a sorted set and its score
+type Member struct {
+	Value []byte
+	Score float64
+}
+
+// SortedSet a set of members ordered by their scores, the members of the sorted set `key` are stored twice:
+//   - `zset:<url escaped key>:m:<member>` holding the score of the member
+//   - `zset:<url escaped key>:s:<encoded score><member>` holding nothing
+//
+// where the encoded score is the hex form of the score bits arranged so that their byte order
+// is the numeric order, so the members are ranged by their scores using a single prefix scan
+type SortedSet struct {
+	provider goukv.Provider
+	members  []byte
+	scores   []byte
+}
+
+// New returns the sorted set of the specified key, goukv.ScansOrdered must report the provider
+// as ordered, otherwise ErrUnorderedProvider is returned
+func New(p goukv.Provider, key []byte) (*SortedSet, error) {
+	if !goukv.ScansOrdered(p) {
+		return nil, ErrUnorderedProvider
+	}
+
+	prefix := keyPrefix + url.QueryEscape(string(key))
+
+	return &SortedSet{
+		provider: p,
+		members:  []byte(prefix + ":m:"),
+		scores:   []byte(prefix + ":s:"),
+	}, nil
+}
+
+// Add sets the score of the specified member and reports whether it is a new member (ZADD),
+// the updates are serialized within the process only, concurrent updates of the same member
+// by other processes may leave a stale score behind
+func (z *SortedSet) Add(member []byte, score float64) (bool, error) {
+	if math.IsNaN(score) {
+		return false, ErrInvalidScore
+	}
+
+	key := z.memberKey(member)
+
+	lock := keylock.Of(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	old, err := z.Score(member)
+	if err != nil && err != goukv.ErrKeyNotFound {
+		return false, err
+	}
+
+	added := err == goukv.ErrKeyNotFound
+	entries := []*goukv.Entry{
+		{Key: key, Value: []byte(strconv.FormatFloat(score, 'g', -1, 64))},
+		{Key: z.scoreKey(member, score), Value: []byte{}},
+	}
+
+	if !added && encodeScore(old) != encodeScore(score) {
+		entries = append(entries, &goukv.Entry{Key: z.scoreKey(member, old)})
+	}
+
+	return added, z.provider.Batch(entries)
+}
+
+// Rem removes the specified members and returns the number of the removed ones (ZREM)
+func (z *SortedSet) Rem(members ...[]byte) (int, error) {
+	removed := 0
+	entries := []*goukv.Entry{}
+	seen := map[string]bool{}
+
+	for _, member := range members {
+		if seen[string(member)] {
+			continue
+		}
+		seen[string(member)] = true
+
+		key := z.memberKey(member)
+
+		lock := keylock.Of(key)
+		lock.Lock()
+		score, err := z.Score(member)
+		lock.Unlock()
+
+		if err == goukv.ErrKeyNotFound {
+			continue
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		removed++
+		entries = append(entries, &goukv.Entry{Key: key}, &goukv.Entry{Key: z.scoreKey(member, score)})
+	}
+
+	if len(entries) < 1 {
+		return 0, nil
+	}
+
+	return removed, z.provider.Batch(entries)
+}
+
+// Score returns the score of the specified member (ZSCORE)
+func (z *SortedSet) Score(member []byte) (float64, error) {
+	val, err := z.provider.Get(z.memberKey(member))
+	if err != nil {
+		return 0, err
+	}
+
+	if val == nil {
+		return 0, goukv.ErrKeyNotFound
+	}
+
+	score, err := strconv.ParseFloat(string(val), 64)
+	if err != nil {
+		return 0, ErrMalformedEntry
+	}
+
+	return score, nil
+}
+
+// RangeByScore returns the members whose scores are within [min, max] ordered by their scores,
+// the members of the same score are ordered by their bytes, limit < 1 means no limit (ZRANGEBYSCORE)
+func (z *SortedSet) RangeByScore(min, max float64, limit int) ([]Member, error) {
+	if math.IsNaN(min) || math.IsNaN(max) {
+		return nil, ErrInvalidScore
+	}
+
+	result := []Member{}
+	upper := encodeScore(max)
+
+	var decodeErr error
+
+	err := z.provider.Scan(goukv.ScanOpts{
+		Prefix:        z.scores,
+		Offset:        z.scoreKey(nil, min),
+		IncludeOffset: true,
+		Scanner: func(k, v []byte) bool {
+			encoded, member, err := z.parseScoreKey(k)
+			if err != nil {
+				decodeErr = err
+				return false
+			}
+
+			if encoded > upper {
+				return false
+			}
+
+			score, err := decodeScore(encoded)
+			if err != nil {
+				decodeErr = err
+				return false
+			}
+
+			result = append(result, Member{Value: member, Score: score})
+
+			return limit < 1 || len(result) < limit
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, decodeErr
+}
+
+// Rank returns the 0 based position of the specified member when the members are ordered by
+// their scores (ZRANK), it scans all the members that precede it
+func (z *SortedSet) Rank(member []byte) (int, error) {
+	score, err := z.Score(member)
+	if err != nil {
+		return 0, err
+	}
+
+	target := string(z.scoreKey(member, score))
+	rank, found := 0, false
+
+	err = z.provider.Scan(goukv.ScanOpts{
+		Prefix: z.scores,
+		Scanner: func(k, v []byte) bool {
+			if string(k) >= target {
+				found = string(k) == target
+				return false
+			}
+
+			rank++
+
+			return true
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if !found {
+		return 0, goukv.ErrKeyNotFound
+	}
+
+	return rank, nil
+}
+
+// memberKey returns the key holding the score of the specified member
+func (z *SortedSet) memberKey(member []byte) []byte {
+	key := make([]byte, 0, len(z.members)+len(member))
+	key = append(key, z.members...)
+
+	return append(key, member...)
+}
+
+// scoreKey returns the key ordering the specified member by the specified score
+func (z *SortedSet) scoreKey(member []byte, score float64) []byte {
+	key := make([]byte, 0, len(z.scores)+scoreLength+len(member))
+	key = append(key, z.scores...)
+	key = append(key, encodeScore(score)...)
+
+	return append(key, member...)
+}
+
+// parseScoreKey splits the specified score key into the encoded score and the member
+func (z *SortedSet) parseScoreKey(k []byte) (string, []byte, error) {
+	if len(k) < len(z.scores)+scoreLength {
+		return "", nil, ErrMalformedEntry
+	}
+
+	k = k[len(z.scores):]
+
+	return string(k[:scoreLength]), append([]byte{}, k[scoreLength:]...), nil
+}
+
+// encodeScore returns the hex form of the score bits, the sign bit of the positive scores is set
+// and all the bits of the negative ones are flipped, so the byte order matches the numeric order
+func encodeScore(score float64) string {
+	if score == 0 {
+		score = 0 // -0 and +0 are the same score
+	}
+
+	bits := math.Float64bits(score)
+	if bits>>63 == 0 {
+		bits |= 1 << 63
+	} else {
+		bits = ^bits
+	}
+
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], bits)
+
+	return hex.EncodeToString(buf[:])
+}
+
+// decodeScore reverses encodeScore
+func decodeScore(encoded string) (float64, error) {
+	buf, err := hex.DecodeString(encoded)
+	if err != nil || len(buf) != 8 {
+		return 0, ErrMalformedEntry
+	}
+
+	bits := binary.BigEndian.Uint64(buf)
+	if bits>>63 == 1 {
+		bits &^= 1 << 63
+	} else {
+		bits = ^bits
+	}
+
+	return math.Float64frombits(bits), nil
+}
@@ -0,0 +1,97 @@
+package zset
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/internal/testutil"
+)
+
+func TestSortedSet(t *testing.T) {
+	testutil.OpenDBAndDo(t, func(db goukv.Provider) {
+		board, err := New(db, []byte("board"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		scores := map[string]float64{"a": 10, "b": -2.5, "c": 0, "d": 10, "e": 1e9, "f": math.Inf(-1)}
+		for member, score := range scores {
+			if added, err := board.Add([]byte(member), score); err != nil || !added {
+				t.Errorf("expected (%s) to be added, found (%v, %v)", member, added, err)
+			}
+		}
+
+		if added, err := board.Add([]byte("e"), 3); err != nil || added {
+			t.Errorf("expected (e) to be updated, found (%v, %v)", added, err)
+		}
+
+		if score, err := board.Score([]byte("e")); err != nil || score != 3 {
+			t.Errorf("expected (3), found (%v, %v)", score, err)
+		}
+
+		members, err := board.RangeByScore(-3, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		found := ""
+		for _, m := range members {
+			found += string(m.Value)
+		}
+
+		if found != "bcead" || members[0].Score != -2.5 {
+			t.Errorf("expected (bcead), found (%s, %v)", found, members)
+		}
+
+		if members, _ := board.RangeByScore(math.Inf(-1), math.Inf(1), 2); len(members) != 2 || string(members[0].Value) != "f" {
+			t.Errorf("unexpected limited range (%v)", members)
+		}
+
+		if rank, err := board.Rank([]byte("a")); err != nil || rank != 4 {
+			t.Errorf("expected (4), found (%v, %v)", rank, err)
+		}
+
+		if removed, err := board.Rem([]byte("a"), []byte("a"), []byte("x")); err != nil || removed != 1 {
+			t.Errorf("expected (1), found (%v, %v)", removed, err)
+		}
+
+		if _, err := board.Rank([]byte("a")); err != goukv.ErrKeyNotFound {
+			t.Errorf("expected (%v), found (%v)", goukv.ErrKeyNotFound, err)
+		}
+
+		if rank, err := board.Rank([]byte("d")); err != nil || rank != 4 {
+			t.Errorf("expected (4), found (%v, %v)", rank, err)
+		}
+	})
+}
+
+func TestScoreEncoding(t *testing.T) {
+	scores := []float64{math.Inf(-1), -1e300, -1, -1e-300, 0, 1e-300, 1, 1e300, math.Inf(1)}
+
+	for i, score := range scores {
+		decoded, err := decodeScore(encodeScore(score))
+		if err != nil || decoded != score {
+			t.Errorf("expected (%v), found (%v, %v)", score, decoded, err)
+		}
+
+		if i > 0 && encodeScore(scores[i-1]) >= encodeScore(score) {
+			t.Errorf("expected (%v) to be encoded before (%v)", scores[i-1], score)
+		}
+	}
+
+	if encodeScore(math.Copysign(0, -1)) != encodeScore(0) {
+		t.Error("expected -0 and +0 to be the same score")
+	}
+}
+
+// unordered a provider that doesn't implement goukv.OrderedScanner
+type unordered struct {
+	goukv.Provider
+}
+
+func TestUnorderedProvider(t *testing.T) {
+	if _, err := New(unordered{}, []byte("board")); err != ErrUnorderedProvider {
+		t.Errorf("expected (%v), found (%v)", ErrUnorderedProvider, err)
+	}
+}
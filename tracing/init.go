@@ -0,0 +1,22 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/alash3al/goukv"
+)
+
+const (
+	name = "tracing"
+)
+
+func init() {
+	goukv.RegisterMiddleware(name, func(dsn *goukv.DSN) (goukv.Middleware, error) {
+		providerName := dsn.GetString("tracing_name")
+		if providerName == "" {
+			providerName = dsn.Scheme()
+		}
+
+		return Middleware(context.Background(), providerName, nil), nil
+	})
+}
@@ -0,0 +1,246 @@
+// Package tracing instruments goukv providers using opentelemetry.
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/internal/scanutil"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/alash3al/goukv/tracing"
+)
+
+// span attributes keys
+const (
+	AttrProvider   = attribute.Key("goukv.provider")
+	AttrOperation  = attribute.Key("goukv.operation")
+	AttrKeyLength  = attribute.Key("goukv.key.length")
+	AttrValueSize  = attribute.Key("goukv.value.size")
+	AttrTTL        = attribute.Key("goukv.ttl")
+	AttrPrefix     = attribute.Key("goukv.scan.prefix")
+	AttrReverse    = attribute.Key("goukv.scan.reverse")
+	AttrScanItems  = attribute.Key("goukv.scan.items")
+	AttrBatchSize  = attribute.Key("goukv.batch.size")
+	AttrSwapped    = attribute.Key("goukv.cas.swapped")
+	AttrResult     = attribute.Key("goukv.result")
+	resultOK       = "ok"
+	resultNotFound = "not_found"
+	resultExpired  = "expired"
+	resultError    = "error"
+)
+
+// Provider binds the context the spans are children of, the operations are traced by the
+// tracing middleware, and the bound context is propagated to the wrapped provider if it
+// implements goukv.ContextBinder
+type Provider struct {
+	goukv.Provider
+
+	provider goukv.Provider
+	name     string
+	tracer   trace.Tracer
+}
+
+// Wrap wraps the specified provider, the spans are labelled by the specified provider name
+// and created using the specified tracer provider, the global one is used if it is nil
+func Wrap(p goukv.Provider, providerName string, tp trace.TracerProvider) *Provider {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	return bind(context.Background(), p, providerName, tp.Tracer(instrumentationName))
+}
+
+// Middleware returns a middleware that creates a span for each operation of the provider labelled
+// by the specified name using the specified tracer provider (the global one if it is nil), the spans
+// are children of the span of the specified context
+func Middleware(ctx context.Context, providerName string, tp trace.TracerProvider) goukv.Middleware {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	return middleware(ctx, providerName, tp.Tracer(instrumentationName))
+}
+
+// bind wraps the specified provider using a middleware bound to the specified context
+func bind(ctx context.Context, p goukv.Provider, providerName string, tracer trace.Tracer) *Provider {
+	return &Provider{
+		Provider: goukv.Wrap(p, middleware(ctx, providerName, tracer)),
+		provider: p,
+		name:     providerName,
+		tracer:   tracer,
+	}
+}
+
+// WithContext implements goukv.ContextBinder
+func (p Provider) WithContext(ctx context.Context) goukv.Provider {
+	return bind(ctx, goukv.WithContext(ctx, p.provider), p.name, p.tracer)
+}
+
+// Unwrap implements goukv.Unwrapper
+func (p Provider) Unwrap() goukv.Provider {
+	return p.Provider
+}
+
+// Open implements goukv.Open
+func (p Provider) Open(dsn *goukv.DSN) (goukv.Provider, error) {
+	db, err := p.provider.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return bind(context.Background(), db, p.name, p.tracer), nil
+}
+
+// CompareAndSwap implements goukv.CompareAndSwapper
+func (p Provider) CompareAndSwap(e *goukv.Entry, old []byte) (bool, error) {
+	return goukv.CompareAndSwap(p.Provider, e, old)
+}
+
+// middleware returns the hooks creating the spans as children of the span of the specified context
+func middleware(ctx context.Context, providerName string, tracer trace.Tracer) goukv.Middleware {
+	start := func(operation string, attrs ...attribute.KeyValue) trace.Span {
+		attrs = append(attrs, AttrProvider.String(providerName), AttrOperation.String(operation))
+
+		_, span := tracer.Start(ctx, "goukv."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attrs...),
+		)
+
+		return span
+	}
+
+	return goukv.Middleware{
+		Put: func(next goukv.PutFunc) goukv.PutFunc {
+			return func(e *goukv.Entry) error {
+				span := start("put",
+					AttrKeyLength.Int(len(e.Key)),
+					AttrValueSize.Int(len(e.Value)),
+					AttrTTL.String(e.TTL.String()),
+				)
+
+				err := next(e)
+				end(span, err)
+
+				return err
+			}
+		},
+
+		Get: func(next goukv.GetFunc) goukv.GetFunc {
+			return func(k []byte) ([]byte, error) {
+				span := start("get", AttrKeyLength.Int(len(k)))
+
+				val, err := next(k)
+				if err == nil {
+					span.SetAttributes(AttrValueSize.Int(len(val)))
+				}
+				end(span, err)
+
+				return val, err
+			}
+		},
+
+		TTL: func(next goukv.TTLFunc) goukv.TTLFunc {
+			return func(k []byte) (*time.Time, error) {
+				span := start("ttl", AttrKeyLength.Int(len(k)))
+
+				t, err := next(k)
+				end(span, err)
+
+				return t, err
+			}
+		},
+
+		Delete: func(next goukv.DeleteFunc) goukv.DeleteFunc {
+			return func(k []byte) error {
+				span := start("delete", AttrKeyLength.Int(len(k)))
+
+				err := next(k)
+				end(span, err)
+
+				return err
+			}
+		},
+
+		Batch: func(next goukv.BatchFunc) goukv.BatchFunc {
+			return func(entries []*goukv.Entry) error {
+				span := start("batch", AttrBatchSize.Int(len(entries)))
+
+				err := next(entries)
+				end(span, err)
+
+				return err
+			}
+		},
+
+		Scan: func(next goukv.ScanFunc) goukv.ScanFunc {
+			return func(opts goukv.ScanOpts) error {
+				span := start("scan",
+					AttrPrefix.String(string(opts.Prefix)),
+					AttrReverse.Bool(opts.ReverseScan),
+				)
+
+				items := scanutil.Count(&opts)
+
+				err := next(opts)
+				span.SetAttributes(AttrScanItems.Int(*items))
+				end(span, err)
+
+				return err
+			}
+		},
+
+		Close: func(next goukv.CloseFunc) goukv.CloseFunc {
+			return func() error {
+				span := start("close")
+
+				err := next()
+				end(span, err)
+
+				return err
+			}
+		},
+
+		CompareAndSwap: func(next goukv.CompareAndSwapFunc) goukv.CompareAndSwapFunc {
+			return func(e *goukv.Entry, old []byte) (bool, error) {
+				span := start("compare_and_swap",
+					AttrKeyLength.Int(len(e.Key)),
+					AttrValueSize.Int(len(e.Value)),
+					AttrTTL.String(e.TTL.String()),
+				)
+
+				swapped, err := next(e, old)
+				if err == nil {
+					span.SetAttributes(AttrSwapped.Bool(swapped))
+				}
+				end(span, err)
+
+				return swapped, err
+			}
+		},
+	}
+}
+
+// end records the result of the operation then ends its span
+func end(span trace.Span, err error) {
+	switch err {
+	case nil:
+		span.SetAttributes(AttrResult.String(resultOK))
+	case goukv.ErrKeyNotFound:
+		span.SetAttributes(AttrResult.String(resultNotFound))
+	case goukv.ErrKeyExpired:
+		span.SetAttributes(AttrResult.String(resultExpired))
+	default:
+		span.SetAttributes(AttrResult.String(resultError))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,126 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alash3al/goukv"
+	"github.com/alash3al/goukv/providers/leveldb"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// binder records the context bound to the provider it wraps
+type binder struct {
+	goukv.Provider
+	ctx *context.Context
+}
+
+func (b binder) WithContext(ctx context.Context) goukv.Provider {
+	*b.ctx = ctx
+
+	return b
+}
+
+func TestSpans(t *testing.T) {
+	dsn, _ := goukv.NewDSN("leveldb://" + t.TempDir())
+	p, err := leveldb.Provider{}.Open(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	var bound context.Context
+	db := Wrap(binder{Provider: p, ctx: &bound}, "leveldb", tp)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	traced := goukv.WithContext(ctx, db)
+
+	traced.Batch([]*goukv.Entry{
+		{Key: []byte("k1"), Value: []byte("v1")},
+		{Key: []byte("k2"), Value: []byte("v2")},
+	})
+	traced.TTL([]byte("not-found"))
+	traced.Scan(goukv.ScanOpts{Prefix: []byte("k"), Scanner: func(k, v []byte) bool { return true }})
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("expected (4) spans, found (%d)", len(spans))
+	}
+
+	attrs := func(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+		result := map[attribute.Key]attribute.Value{}
+		for _, kv := range span.Attributes() {
+			result[kv.Key] = kv.Value
+		}
+		return result
+	}
+
+	for _, span := range spans[:3] {
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("expected (%s) to be a child of the bound span", span.Name())
+		}
+
+		if attrs(span)[AttrProvider].AsString() != "leveldb" {
+			t.Errorf("expected (%s) to carry the provider name", span.Name())
+		}
+	}
+
+	if batch := attrs(spans[0]); spans[0].Name() != "goukv.batch" || batch[AttrBatchSize].AsInt64() != 2 {
+		t.Errorf("unexpected batch span (%s) %v", spans[0].Name(), batch)
+	}
+
+	if ttl := attrs(spans[1]); ttl[AttrResult].AsString() != resultNotFound || spans[1].Status().Code == codes.Error {
+		t.Errorf("expected a not found ttl, found %v", ttl)
+	}
+
+	if scan := attrs(spans[2]); scan[AttrScanItems].AsInt64() != 2 || scan[AttrPrefix].AsString() != "k" {
+		t.Errorf("unexpected scan span %v", scan)
+	}
+
+	if bound == nil || trace.SpanContextFromContext(bound).SpanID() != parent.SpanContext().SpanID() {
+		t.Error("expected the bound context to be propagated to the wrapped provider")
+	}
+}
+
+func TestDSNMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	db, err := goukv.Open("leveldb", "leveldb://"+t.TempDir()+"?middlewares=tracing&tracing_name=dsn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.Put(&goukv.Entry{Key: []byte("k1"), Value: []byte("v1")})
+	if _, err := goukv.CompareAndSwap(db, &goukv.Entry{Key: []byte("k1"), Value: []byte("v2")}, []byte("v1")); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected (3) spans, found (%d)", len(spans))
+	}
+
+	for i, name := range []string{"goukv.put", "goukv.compare_and_swap", "goukv.close"} {
+		if spans[i].Name() != name {
+			t.Errorf("expected span (%s), found (%s)", name, spans[i].Name())
+		}
+	}
+
+	for _, kv := range spans[0].Attributes() {
+		if kv.Key == AttrProvider && kv.Value.AsString() != "dsn" {
+			t.Errorf("expected the provider name to be (dsn), found (%s)", kv.Value.AsString())
+		}
+	}
+}
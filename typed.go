@@ -0,0 +1,129 @@
+package goukv
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"time"
+)
+
+// the stdlib based codecs, see the codecs package for msgpack and protobuf
+var (
+	JSONCodec Codec = jsonCodec{}
+	GobCodec  Codec = gobCodec{}
+)
+
+// Codec marshals the values of a typed store, both methods receive a pointer to the value
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Typed a store of T values on top of a provider
+type Typed[T any] struct {
+	provider Provider
+	codec    Codec
+}
+
+// NewTyped initializes a new typed store on top of the specified provider using the specified codec
+func NewTyped[T any](p Provider, codec Codec) *Typed[T] {
+	return &Typed[T]{
+		provider: p,
+		codec:    codec,
+	}
+}
+
+// Provider returns the underlying provider
+func (t *Typed[T]) Provider() Provider {
+	return t.provider
+}
+
+// Put stores the specified value, zero ttl means no expiration
+func (t *Typed[T]) Put(k []byte, v T, ttl time.Duration) error {
+	data, err := t.codec.Marshal(&v)
+	if err != nil {
+		return err
+	}
+
+	return t.provider.Put(&Entry{Key: k, Value: data, TTL: ttl})
+}
+
+// Get fetches the value of the specified key, goukv.ErrKeyNotFound is returned
+// if the provider returns neither a value nor an error
+func (t *Typed[T]) Get(k []byte) (T, error) {
+	var v T
+
+	data, err := t.provider.Get(k)
+	if err != nil {
+		return v, err
+	}
+
+	if data == nil {
+		return v, ErrKeyNotFound
+	}
+
+	err = t.codec.Unmarshal(data, &v)
+
+	return v, err
+}
+
+// TTL returns the expiration time of the specified key
+func (t *Typed[T]) TTL(k []byte) (*time.Time, error) {
+	return t.provider.TTL(k)
+}
+
+// Delete deletes the specified key
+func (t *Typed[T]) Delete(k []byte) error {
+	return t.provider.Delete(k)
+}
+
+// Scan decodes the values matching the specified options (its Scanner is ignored) and passes them to the
+// specified scanner until it returns false, the scan stops at the first value that can't be decoded
+func (t *Typed[T]) Scan(opts ScanOpts, scanner func(k []byte, v T) bool) error {
+	var decodeErr error
+
+	opts.Scanner = func(k, data []byte) bool {
+		var v T
+
+		if err := t.codec.Unmarshal(data, &v); err != nil {
+			decodeErr = err
+			return false
+		}
+
+		return scanner(k, v)
+	}
+
+	if err := t.provider.Scan(opts); err != nil {
+		return err
+	}
+
+	return decodeErr
+}
+
+// jsonCodec implements Codec using encoding/json
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// gobCodec implements Codec using encoding/gob
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}